@@ -16,17 +16,44 @@ import (
 	provider "github.com/terraform-providers/terraform-provider-oci/oci"
 )
 
+// A flag that may be specified more than once, e.g. '-filter=<expression> -filter=<expression>'
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func main() {
-	var command = flag.String("command", "", "Command to run. Supported commands include: 'export' and 'list_export_resources'")
-	var compartmentId = flag.String("compartment_id", "", "[export] OCID of a compartment to export. If no compartment id nor name is specified, the root compartment will be used.")
-	var compartmentName = flag.String("compartment_name", "", "[export] The name of a compartment to export.")
-	var outputPath = flag.String("output_path", "", "[export] Path to output generated configurations and state files of the exported compartment")
-	var services = flag.String("services", "", "[export] Comma-separated list of service resources to export. By default, all compartment-scope resources are exported.")
+	var command = flag.String("command", "", "Command to run. Supported commands include: 'export', 'drift' and 'list_export_resources'")
+	var compartmentId = flag.String("compartment_id", "", "[export][drift] OCID of a compartment to export. If no compartment id nor name is specified, the root compartment will be used.")
+	var compartmentName = flag.String("compartment_name", "", "[export][drift] The name of a compartment to export.")
+	var outputPath = flag.String("output_path", "", "[export][drift] Path to output generated configurations and state files of the exported compartment, or the drift report")
+	var services = flag.String("services", "", "[export][drift] Comma-separated list of service resources to export. By default, all compartment-scope resources are exported.")
 	var ids = flag.String("ids", "", "[export] Comma-separated list of resource IDs to export. The ID could either be an OCID or a Terraform import ID. By default, all resources are exported.")
 	var generateStateFile = flag.Bool("generate_state", false, "[export][experimental] Set this to import the discovered resources into a state file along with the Terraform configuration")
+	var generateImports = flag.Bool("generate_imports", false, "[export] Set this to generate import.sh and import.ps1 scripts that import the discovered resources with 'terraform import', for use with any backend")
+	var generateGraph = flag.Bool("generate_graph", false, "[export] Set this to write the dependency graph of the discovered resources to resources.dot and resources.json, with containment and attribute reference edges")
+	var discoverOnly = flag.Bool("discover_only", false, "[export] Set this to only discover the resources and write the dependency graph and export report, without generating any Terraform configuration")
+	var lookupExternalReferences = flag.Bool("lookup_external_references", false, "[export] Set this to replace the OCIDs of resources that are referenced but not exported, such as platform images and subnets of other compartments, with data sources that look them up")
 	var help = flag.Bool("help", false, "Prints usage options")
 	var tfVersion = flag.String("tf_version", "0.12", "The version of terraform syntax to generate for configurations. The state file will be written in v0.12 only. The allowed values are :\n * 0.11\n * 0.12")
+	var parallelism = flag.Int("parallelism", 1, "[export][drift] The number of threads to use for resource discovery. By default the value is 1")
+	var format = flag.String("format", "hcl", "[export] The syntax of the generated configuration files. The allowed values are :\n * hcl - native syntax .tf files\n * json - JSON syntax .tf.json files")
+	var moduleLayout = flag.String("module_layout", "", "[export] Generate the configuration as Terraform modules instantiated by a root module. The allowed values are :\n * service - a module for each service\n * compartment - a module for each compartment")
+	var recursive = flag.Bool("recursive", false, "[export] Set this to also export the resources of all sub-compartments of the exported compartment. Each sub-compartment is generated in a sub-directory of its parent compartment's output_path")
+	var continueOnError = flag.Bool("continue_on_error", false, "[export] Set this to skip the resources that fail to be discovered, along with any resources under them, and export the rest of the compartment. The skipped resources are listed at the end, and the command exits with an error")
 
+	var filters stringListFlag
+	flag.Var(&filters, "filter", "[export] Filter expression that discovered resources must match to be exported. May be specified more than once. See the resource discovery documentation for the syntax, e.g. 'defined_tags.Team.Owner=payments' or 'exclude:type=oci_core_instance;state=TERMINATED'")
+	var nameTemplate = flag.String("name_template", "", "[export] Template of the Terraform names of the discovered resources, over their attributes and tags, e.g. '{{tag \"Ops.Name\"}}_{{.display_name}}'. Resources that the template has no value for are named by default")
+	var nameMappingFile = flag.String("name_mapping_file", "", "[export] Path to a JSON file that maps resource OCIDs to Terraform names. Resources in the file keep their names, and the file is updated with the names of the discovered resources")
+	var regions = flag.String("regions", "", "[export] Comma-separated list of regions to export the compartment from, e.g. 'phx,iad'. Each region is exported to a sub-directory of output_path, with resources that use a provider alias for the region. Tenancy-scope resources are only exported from the home region, which is exported for them even if it is not listed")
+	var stateFile = flag.String("state_file", "", "[drift] Path to the terraform.tfstate file to compare with the resources discovered in the compartment")
 	flag.Parse()
 	provider.PrintVersion()
 
@@ -55,12 +82,47 @@ func main() {
 				log.Printf("[ERROR]: Invalid tf_version '%s', supported values: 0.11, 0.12\n", *tfVersion)
 				os.Exit(1)
 			}
+
+			if *parallelism < 1 {
+				log.Printf("[ERROR]: Invalid parallelism '%d', value must be at least 1\n", *parallelism)
+				os.Exit(1)
+			}
+
+			exportFormat := provider.ExportFormatEnum(*format)
+			if exportFormat != provider.ExportFormatHcl && exportFormat != provider.ExportFormatJson {
+				log.Printf("[ERROR]: Invalid format '%s', supported values: hcl, json\n", *format)
+				os.Exit(1)
+			}
+
+			layout := provider.ModuleLayoutEnum(*moduleLayout)
+			if layout != provider.ModuleLayoutNone && layout != provider.ModuleLayoutService && layout != provider.ModuleLayoutCompartment {
+				log.Printf("[ERROR]: Invalid module_layout '%s', supported values: service, compartment\n", *moduleLayout)
+				os.Exit(1)
+			}
+
+			if *discoverOnly && (*generateStateFile || *generateImports || *nameMappingFile != "") {
+				log.Println("[ERROR]: discover_only can not be used with generate_state, generate_imports or name_mapping_file, since no configuration is generated")
+				os.Exit(1)
+			}
+
 			args := &provider.ExportCommandArgs{
-				CompartmentId:   compartmentId,
-				CompartmentName: compartmentName,
-				OutputDir:       outputPath,
-				GenerateState:   *generateStateFile,
-				TFVersion:       &terraformVersion,
+				CompartmentId:            compartmentId,
+				CompartmentName:          compartmentName,
+				OutputDir:                outputPath,
+				GenerateState:            *generateStateFile,
+				GenerateImports:          *generateImports,
+				GenerateGraph:            *generateGraph,
+				DiscoverOnly:             *discoverOnly,
+				LookupExternalReferences: *lookupExternalReferences,
+				TFVersion:                &terraformVersion,
+				Parallelism:              *parallelism,
+				Recursive:                *recursive,
+				ModuleLayout:             layout,
+				Format:                   exportFormat,
+				Filters:                  filters,
+				ContinueOnError:          *continueOnError,
+				NameTemplate:             *nameTemplate,
+				NameMappingFile:          *nameMappingFile,
 			}
 
 			if services != nil && *services != "" {
@@ -71,10 +133,36 @@ func main() {
 				args.IDs = strings.Split(*ids, ",")
 			}
 
+			if regions != nil && *regions != "" {
+				args.Regions = strings.Split(*regions, ",")
+			}
+
 			if err := provider.RunExportCommand(args); err != nil {
 				color.Red("%v", err)
 				os.Exit(1)
 			}
+		case "drift":
+			if *parallelism < 1 {
+				log.Printf("[ERROR]: Invalid parallelism '%d', value must be at least 1\n", *parallelism)
+				os.Exit(1)
+			}
+
+			args := &provider.DriftCommandArgs{
+				CompartmentId:   compartmentId,
+				CompartmentName: compartmentName,
+				StateFile:       stateFile,
+				OutputDir:       outputPath,
+				Parallelism:     *parallelism,
+			}
+
+			if services != nil && *services != "" {
+				args.Services = strings.Split(*services, ",")
+			}
+
+			if err := provider.RunDriftCommand(args); err != nil {
+				color.Red("%v", err)
+				os.Exit(1)
+			}
 		case "list_export_resources":
 			if err := provider.RunListExportableResourcesCommand(); err != nil {
 				log.Printf("%v", err)
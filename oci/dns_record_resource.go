@@ -12,6 +12,8 @@ package oci
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 
@@ -27,6 +29,9 @@ func init() {
 
 func DnsRecordResource() *schema.Resource {
 	return &schema.Resource{
+		Importer: &schema.ResourceImporter{
+			State: schema.ImportStatePassthrough,
+		},
 		Timeouts: DefaultTimeout,
 		Create:   createDnsRecord,
 		Read:     readDnsRecord,
@@ -198,6 +203,14 @@ func (s *DnsRecordResourceCrud) Create() error {
 func (s *DnsRecordResourceCrud) Get() error {
 	request := oci_dns.GetRRSetRequest{}
 
+	// Records that are imported only have a composite ID, so the attributes needed to find the record are taken from it
+	if zoneNameOrId, domain, rtype, recordHash, err := parseDnsRecordCompositeId(s.D.Id()); err == nil {
+		s.D.Set("zone_name_or_id", zoneNameOrId)
+		s.D.Set("domain", domain)
+		s.D.Set("rtype", rtype)
+		s.D.Set("record_hash", recordHash)
+	}
+
 	zoneNameOrId := s.D.Get("zone_name_or_id").(string)
 	request.ZoneNameOrId = &zoneNameOrId
 
@@ -371,6 +384,30 @@ func findItem(rc *[]oci_dns.Record, r *schema.ResourceData) (*oci_dns.Record, er
 	return nil, fmt.Errorf("target %s record could not be matched against data %s\nfrom set %+v", rType, rData, rc)
 }
 
+func getDnsRecordCompositeId(zoneNameOrId string, domain string, rtype string, recordHash string) string {
+	zoneNameOrId = url.PathEscape(zoneNameOrId)
+	domain = url.PathEscape(domain)
+	rtype = url.PathEscape(rtype)
+	recordHash = url.PathEscape(recordHash)
+	compositeId := "zoneNameOrId/" + zoneNameOrId + "/domain/" + domain + "/rtype/" + rtype + "/recordHash/" + recordHash
+	return compositeId
+}
+
+func parseDnsRecordCompositeId(compositeId string) (zoneNameOrId string, domain string, rtype string, recordHash string, err error) {
+	parts := strings.Split(compositeId, "/")
+	match, _ := regexp.MatchString("zoneNameOrId/.*/domain/.*/rtype/.*/recordHash/.*", compositeId)
+	if !match || len(parts) != 8 {
+		err = fmt.Errorf("illegal compositeId %s encountered", compositeId)
+		return
+	}
+	zoneNameOrId, _ = url.PathUnescape(parts[1])
+	domain, _ = url.PathUnescape(parts[3])
+	rtype, _ = url.PathUnescape(parts[5])
+	recordHash, _ = url.PathUnescape(parts[7])
+
+	return
+}
+
 // Match dns service transforms of rdata
 func normalizeRData(rtype, rdata string) string {
 	switch rtype {
@@ -90,7 +90,12 @@ func TestDnsRecordsResource_basic(t *testing.T) {
 					func(s *terraform.State) (err error) {
 						resId, err = fromInstanceState(s, resourceName, "id")
 						if isEnableExportCompartment, _ := strconv.ParseBool(getEnvSettingWithDefault("enable_export_compartment", "false")); isEnableExportCompartment {
-							if errExport := testExportCompartmentWithResourceName(&resId, &compartmentId, resourceName); errExport != nil {
+							// Discovered records are identified by their composite ID
+							zoneId, _ := fromInstanceState(s, "oci_dns_zone.test_zone", "id")
+							domain, _ := fromInstanceState(s, resourceName, "domain")
+							rtype, _ := fromInstanceState(s, resourceName, "rtype")
+							compositeId := getDnsRecordCompositeId(zoneId, domain, rtype, resId)
+							if errExport := testExportCompartmentWithResourceName(&compositeId, &compartmentId, resourceName); errExport != nil {
 								return errExport
 							}
 						}
@@ -0,0 +1,217 @@
+package oci
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const tfvarsExampleFile = "terraform.tfvars.example"
+
+// A variable generated for an attribute whose discovered value can't be written to the configuration, either because
+// the value could not be discovered or because it is a secret
+type attributeVar struct {
+	resource   string // The Terraform reference of the resource, e.g. 'oci_database_autonomous_database.export_db'
+	attribute  string // The path of the attribute in the resource, e.g. 'admin_password' or 'source_details.password'
+	sensitive  bool
+	discovered bool // Whether a value was discovered, which is only the case for secrets that are kept out of the configuration
+	optional   bool // Optional attributes that weren't discovered are given a null default, so they only need to be set if used
+	valueType  schema.ValueType
+}
+
+func (v *attributeVar) getDescription() string {
+	description := fmt.Sprintf("Required attribute '%s' of %s", v.attribute, v.resource)
+	if v.sensitive {
+		description = fmt.Sprintf("Sensitive attribute '%s' of %s", v.attribute, v.resource)
+	}
+
+	if !v.discovered {
+		description = description + ", which could not be discovered"
+	}
+	return description
+}
+
+// Returns the type constraint of the variable, which is only needed for lists and maps since Terraform v0.11 takes
+// variables without a type to be strings
+func (v *attributeVar) getType() string {
+	varType := ""
+	switch v.valueType {
+	case schema.TypeList, schema.TypeSet:
+		varType = "list"
+	case schema.TypeMap:
+		varType = "map"
+	default:
+		return ""
+	}
+
+	if _, isHcl11 := tfHclVersion.(*TfHclVersion11); isHcl11 || exportFormat == ExportFormatJson {
+		return fmt.Sprintf("%q", varType)
+	}
+	return varType
+}
+
+// Returns an empty value of the attribute's type for the example tfvars file
+func (v *attributeVar) getExampleValue() string {
+	switch v.valueType {
+	case schema.TypeBool:
+		return "false"
+	case schema.TypeInt, schema.TypeFloat:
+		return "0"
+	case schema.TypeList, schema.TypeSet:
+		return "[]"
+	case schema.TypeMap:
+		return "{}"
+	default:
+		return "\"\""
+	}
+}
+
+// Replaces the required attributes that could not be discovered, and the sensitive attributes, of the resources
+// discovered in a compartment with variables. Each attribute gets its own variable, which is set by the generated
+// configuration through the compartment's references.
+func generateAttributeVars(compartment *exportCompartment) {
+	for _, step := range compartment.generateConfigSteps {
+		for _, resource := range step.discoveredResources {
+			if resourceSchema, exists := resourcesMap[resource.terraformClass]; exists {
+				compartment.addAttributeVars(resource, resource.sourceAttributes, resourceSchema, "")
+			}
+		}
+	}
+}
+
+func (compartment *exportCompartment) addAttributeVars(resource *OCIResource, sourceAttributes map[string]interface{}, resourceSchema *schema.Resource, prefix string) {
+	sortedKeys := make([]string, 0, len(resourceSchema.Schema))
+	for key := range resourceSchema.Schema {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, tfAttribute := range sortedKeys {
+		tfSchema := resourceSchema.Schema[tfAttribute]
+		if tfSchema.Deprecated != "" || tfSchema.Removed != "" || (!tfSchema.Required && !tfSchema.Optional) {
+			continue
+		}
+
+		attributeVal := sourceAttributes[tfAttribute]
+		if nestedResource, isNested := tfSchema.Elem.(*schema.Resource); isNested {
+			switch v := attributeVal.(type) {
+			case map[string]interface{}:
+				compartment.addAttributeVars(resource, v, nestedResource, fmt.Sprintf("%s%s.", prefix, tfAttribute))
+				continue
+			case []interface{}:
+				for idx, item := range v {
+					blockPrefix := fmt.Sprintf("%s%s.", prefix, tfAttribute)
+					if len(v) > 1 {
+						blockPrefix = fmt.Sprintf("%s%s.%d.", prefix, tfAttribute, idx)
+					}
+					if block, ok := item.(map[string]interface{}); ok {
+						compartment.addAttributeVars(resource, block, nestedResource, blockPrefix)
+					}
+				}
+				continue
+			}
+
+			// Blocks can't be set by a variable, so blocks that weren't discovered are left as comments in the configuration
+			continue
+		}
+
+		discovered := attributeVal != nil
+		switch {
+		case !tfSchema.Sensitive && (discovered || !tfSchema.Required):
+			continue
+		case tfSchema.Sensitive && !discovered && !tfSchema.Required:
+			// Terraform v0.11 variables can't have a null default, so undiscovered optional secrets are left as comments
+			if _, isHcl11 := tfHclVersion.(*TfHclVersion11); isHcl11 {
+				continue
+			}
+		}
+
+		v := &attributeVar{
+			resource:   resource.getTerraformReference(),
+			attribute:  prefix + tfAttribute,
+			sensitive:  tfSchema.Sensitive,
+			discovered: discovered,
+			optional:   !tfSchema.Required && !discovered,
+			valueType:  tfSchema.Type,
+		}
+		varName := compartment.getAttributeVarName(resource, v.attribute)
+		compartment.vars[varName] = ""
+		compartment.attributeVars[varName] = v
+
+		// The attribute is set to a placeholder that is replaced by the variable, the same way as any other reference
+		placeholder := fmt.Sprintf("<<%s>>", varName)
+		sourceAttributes[tfAttribute] = placeholder
+		compartment.referenceMap[placeholder] = tfHclVersion.getVarHclString(varName)
+		resource.attributeVars = append(resource.attributeVars, v)
+	}
+}
+
+// Returns a variable name for an attribute that is unique within the compartment
+// e.g. 'export_db_admin_password' for the 'admin_password' of 'oci_database_autonomous_database.export_db'
+func (compartment *exportCompartment) getAttributeVarName(resource *OCIResource, attribute string) string {
+	baseName := moduleVariableNameRegex.ReplaceAllString(fmt.Sprintf("%s_%s", resource.terraformName, strings.Replace(attribute, ".", "_", -1)), "_")
+	varName := baseName
+	for count := 2; ; count++ {
+		if _, exists := compartment.vars[varName]; !exists {
+			return varName
+		}
+		varName = fmt.Sprintf("%s_%d", baseName, count)
+	}
+}
+
+// Returns the summary statements listing the variables that need to be set, if any
+func getAttributeVarsStatements(outputDir string, attributeVars map[string]*attributeVar) []string {
+	if len(attributeVars) == 0 {
+		return []string{}
+	}
+
+	statements := []string{
+		"",
+		fmt.Sprintf("Warning: The following attributes are set by variables, which need to be set before running 'terraform plan'. See '%s%s%s'.", outputDir, string(os.PathSeparator), tfvarsExampleFile),
+	}
+	for _, varName := range getSortedAttributeVarNames(attributeVars) {
+		statements = append(statements, fmt.Sprintf("- %s: %s", varName, attributeVars[varName].getDescription()))
+	}
+	return statements
+}
+
+// Writes an example tfvars file that lists the variables of the attributes that could not be discovered or are secrets
+func generateTfvarsExampleFile(outputDir string, attributeVars map[string]*attributeVar) error {
+	builder := &strings.Builder{}
+	builder.WriteString("## Set the following variables, and rename this file to terraform.tfvars\n\n")
+	for _, varName := range getSortedAttributeVarNames(attributeVars) {
+		v := attributeVars[varName]
+		builder.WriteString(fmt.Sprintf("# %s\n", v.getDescription()))
+		if v.optional {
+			builder.WriteString(fmt.Sprintf("#%s = %s\n\n", varName, v.getExampleValue()))
+		} else {
+			builder.WriteString(fmt.Sprintf("%s = %s\n\n", varName, v.getExampleValue()))
+		}
+	}
+
+	return writeExportConfigFile(outputDir, tfvarsExampleFile, builder.String())
+}
+
+func getSortedAttributeVarNames(attributeVars map[string]*attributeVar) []string {
+	result := make([]string, 0, len(attributeVars))
+	for varName := range attributeVars {
+		result = append(result, varName)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Returns the required attributes of a resource that could not be discovered, and were replaced by variables
+func (ociRes *OCIResource) getUndiscoveredAttributes() []string {
+	result := []string{}
+	for _, v := range ociRes.attributeVars {
+		if !v.discovered && !v.optional {
+			result = append(result, v.attribute)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,140 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// Adds a required attribute that is never discovered and a secret to the test parent schema, and makes 'a_string' a secret
+func addTestAttributeVarsSchema() {
+	parentSchema := resourcesMap["oci_test_parent"].Schema
+	parentSchema["a_required"] = &schema.Schema{Type: schema.TypeString, Required: true}
+	parentSchema["a_password"] = &schema.Schema{Type: schema.TypeString, Optional: true, Sensitive: true}
+	parentSchema["a_string"].Sensitive = true
+}
+
+func TestUnitGenerateAttributeVars(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	addTestAttributeVarsSchema()
+	parentSchema := resourcesMap["oci_test_parent"].Schema
+	parentSchema["a_secret_map"] = &schema.Schema{Type: schema.TypeMap, Optional: true, Sensitive: true, Elem: schema.TypeString}
+	parentSchema["a_required_block"] = &schema.Schema{Type: schema.TypeList, Required: true, Elem: &schema.Resource{Schema: map[string]*schema.Schema{"name": {Type: schema.TypeString, Optional: true}}}}
+	tfHclVersion = &TfHclVersion12{}
+
+	newParent := func(terraformClass string, terraformName string) *OCIResource {
+		return &OCIResource{
+			sourceAttributes:  map[string]interface{}{"compartment_id": "ocid1.compartment.abc", "a_string": "secret", "a_secret_map": map[string]interface{}{"key": "secret"}},
+			TerraformResource: TerraformResource{terraformClass: terraformClass, terraformName: terraformName},
+		}
+	}
+	parent := newParent("oci_test_parent", "export_parent")
+
+	compartment := newExportCompartment("ocid1.compartment.abc", "", "export")
+	compartment.referenceMap = map[string]string{}
+	compartment.vars = map[string]string{"export_parent_a_required": "\"existing\""}
+	compartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{parent}}}
+	generateAttributeVars(compartment)
+
+	// Variable names are unique within the compartment
+	assert.Equal(t, []string{"export_parent_a_password", "export_parent_a_required_2", "export_parent_a_secret_map", "export_parent_a_string"}, getSortedAttributeVarNames(compartment.attributeVars))
+	assert.Equal(t, "var.export_parent_a_required_2", compartment.referenceMap[parent.sourceAttributes["a_required"].(string)])
+	assert.Equal(t, "var.export_parent_a_string", compartment.referenceMap[parent.sourceAttributes["a_string"].(string)])
+	assert.Equal(t, "ocid1.compartment.abc", parent.sourceAttributes["compartment_id"])
+
+	// Secrets in maps and lists are replaced by a variable of the same type, and blocks are never replaced by variables
+	assert.Equal(t, "var.export_parent_a_secret_map", compartment.referenceMap[parent.sourceAttributes["a_secret_map"].(string)])
+	assert.Equal(t, "map", compartment.attributeVars["export_parent_a_secret_map"].getType())
+	assert.Equal(t, "", compartment.attributeVars["export_parent_a_string"].getType())
+	assert.Nil(t, parent.sourceAttributes["a_required_block"])
+
+	// The example values match the types of the attributes
+	assert.Equal(t, "{}", compartment.attributeVars["export_parent_a_secret_map"].getExampleValue())
+	assert.Equal(t, "\"\"", compartment.attributeVars["export_parent_a_string"].getExampleValue())
+	assert.Equal(t, "false", (&attributeVar{valueType: schema.TypeBool}).getExampleValue())
+	assert.Equal(t, "0", (&attributeVar{valueType: schema.TypeInt}).getExampleValue())
+	assert.Equal(t, "[]", (&attributeVar{valueType: schema.TypeSet}).getExampleValue())
+
+	outputDir, err := ioutil.TempDir("", "attributeVars")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(outputDir)
+	assert.NoError(t, generateVarsFile(compartment.vars, compartment.attributeVars, &outputDir))
+	varsConfig, err := ioutil.ReadFile(filepath.Join(outputDir, varsFile))
+	assert.NoError(t, err)
+	assert.Contains(t, string(varsConfig), "# Sensitive attribute 'a_secret_map' of oci_test_parent.export_parent\nvariable export_parent_a_secret_map {\n  type = map\n}\n")
+
+	assert.Equal(t, "Required attribute 'a_required' of oci_test_parent.export_parent, which could not be discovered", compartment.attributeVars["export_parent_a_required_2"].getDescription())
+	assert.Equal(t, "Sensitive attribute 'a_string' of oci_test_parent.export_parent", compartment.attributeVars["export_parent_a_string"].getDescription())
+	assert.True(t, compartment.attributeVars["export_parent_a_password"].optional)
+	assert.Equal(t, []string{"a_required"}, parent.getUndiscoveredAttributes())
+
+	// Terraform v0.11 variables can't be null, so optional secrets that weren't discovered are left out
+	tfHclVersion = &TfHclVersion11{}
+	compartment = newExportCompartment("ocid1.compartment.abc", "", "export")
+	compartment.referenceMap = map[string]string{}
+	compartment.vars = map[string]string{}
+	compartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{newParent("oci_test_parent", "export_parent")}}}
+	generateAttributeVars(compartment)
+	assert.Equal(t, []string{"export_parent_a_required", "export_parent_a_secret_map", "export_parent_a_string"}, getSortedAttributeVarNames(compartment.attributeVars))
+	assert.Equal(t, "\"map\"", compartment.attributeVars["export_parent_a_secret_map"].getType())
+}
+
+// Test that undiscoverable and sensitive attributes are generated as variables, along with an example tfvars file
+func TestUnitRunExportCommand_attributeVars(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	addTestAttributeVarsSchema()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	readFile := func(fileName string) string {
+		contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName))
+		if err != nil {
+			t.Fatalf("no %s file generated", fileName)
+		}
+		return string(contents)
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		OutputDir:     &outputDir,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	config := readFile("compartment_testing.tf")
+	assert.Contains(t, config, "a_required = var.export_string1_a_required")
+	assert.Contains(t, config, "a_password = var.export_string1_a_password")
+	assert.Regexp(t, `a_string\s+= var.export_string1_a_string`, config)
+	assert.NotContains(t, config, "<<")
+
+	varsConfig := readFile(varsFile)
+	assert.Contains(t, varsConfig, "# Required attribute 'a_required' of oci_test_parent.export_string1, which could not be discovered\nvariable export_string1_a_required {}")
+	assert.Contains(t, varsConfig, "# Sensitive attribute 'a_string' of oci_test_parent.export_string1\nvariable export_string1_a_string {}")
+	assert.Contains(t, varsConfig, "variable export_string1_a_password { default = null }")
+
+	tfvarsExample := readFile(tfvarsExampleFile)
+	assert.Equal(t, len(parentResources)*2, strings.Count(tfvarsExample, "\nexport_string"))
+	assert.Equal(t, len(parentResources), strings.Count(tfvarsExample, "\n#export_string"))
+	assert.Contains(t, tfvarsExample, "# Sensitive attribute 'a_string' of oci_test_parent.export_string1\nexport_string1_a_string = \"\"")
+}
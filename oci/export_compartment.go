@@ -5,17 +5,18 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/hashicorp/hcl2/hclwrite"
 	"github.com/hashicorp/terraform/backend/local"
-	"github.com/mitchellh/cli"
 
-	"github.com/hashicorp/terraform/command"
 	"github.com/hashicorp/terraform/helper/schema"
 	oci_common "github.com/oracle/oci-go-sdk/common"
 	oci_identity "github.com/oracle/oci-go-sdk/identity"
@@ -26,20 +27,27 @@ const (
 	defaultTmpStateFile             = "terraform.tfstate.tmp"
 	varsFile                        = "vars.tf"
 	providerFile                    = "provider.tf"
+	outputsFile                     = "outputs.tf"
+	remoteStateFile                 = "remote_state.tf"
+	compartmentTreeStepName         = "compartment_tree" // Reported for errors while finding the sub-compartments of a recursive export
 	missingRequiredAttributeWarning = `Warning: There are one or more 'Required' attributes for which a value could not be discovered.
 This may be expected behavior from the service, which may prevent discovery of certain sensitive attributes or secrets.
 Run 'terraform plan' against the generated configuration files to get more information about the missing values.`
 )
 
+// The following maps may be updated by concurrent resource discovery; use the lock next to each map when accessing it
 var referenceMap map[string]string
+var referenceMapLock sync.RWMutex
 var vars map[string]string
+var varsLock sync.Mutex
 var resourceNameCount map[string]int
+var resourceNameCountLock sync.Mutex
 var resourcesMap map[string]*schema.Resource
 var datasourcesMap map[string]*schema.Resource
 var compartmentScopeServices []string
 var isMissingRequiredAttributes bool
-var exportConfigProvider oci_common.ConfigurationProvider
 var tfHclVersion TfHclVersion
+var ocidResourceTypeRegex = regexp.MustCompile(`^ocid[0-9]+\.([a-z0-9]+)\.`)
 
 func init() {
 	resourceNameCount = map[string]int{}
@@ -55,6 +63,20 @@ func init() {
 	isMissingRequiredAttributes = false
 }
 
+// Maps a discovered value (e.g. an OCID) to the interpolation that should replace it in generated configurations
+func addReference(value string, reference string) {
+	referenceMapLock.Lock()
+	defer referenceMapLock.Unlock()
+	referenceMap[value] = reference
+}
+
+// Adds a variable and its default value to the generated vars file
+func addVar(name string, defaultValue string) {
+	varsLock.Lock()
+	defer varsLock.Unlock()
+	vars[name] = defaultValue
+}
+
 func printResourceGraphResources(resourceGraphs map[string]TerraformResourceGraph, scope string) error {
 	for graphName, resourceGraph := range resourceGraphs {
 		// Need a set here because the same resource type may have multiple associations in the same graph
@@ -106,13 +128,26 @@ func RunListExportableResourcesCommand() error {
 }
 
 type ExportCommandArgs struct {
-	CompartmentId   *string
-	CompartmentName *string
-	IDs             []string
-	Services        []string
-	OutputDir       *string
-	GenerateState   bool
-	TFVersion       *TfHclVersion
+	CompartmentId            *string
+	CompartmentName          *string
+	IDs                      []string
+	Filters                  []string
+	ContinueOnError          bool
+	NameTemplate             string // Template of the Terraform names of discovered resources, e.g. '{{.display_name}}'
+	NameMappingFile          string // Path of a JSON file that maps resource IDs to Terraform names, which is updated by each export
+	Services                 []string
+	Regions                  []string // Regions to export the compartment from, each into its own sub-directory of OutputDir
+	OutputDir                *string
+	GenerateState            bool
+	GenerateImports          bool
+	GenerateGraph            bool // Write the dependency graph of the discovered resources to resources.dot and resources.json
+	DiscoverOnly             bool // Discover the resources without generating any configuration, e.g. to only write the dependency graph
+	LookupExternalReferences bool // Replace the OCIDs of resources outside of the export with data sources that look them up
+	TFVersion                *TfHclVersion
+	Parallelism              int
+	Recursive                bool
+	ModuleLayout             ModuleLayoutEnum
+	Format                   ExportFormatEnum
 }
 
 func RunExportCommand(args *ExportCommandArgs) error {
@@ -120,7 +155,35 @@ func RunExportCommand(args *ExportCommandArgs) error {
 	datasourcesMap = DataSourcesMap()
 
 	tfHclVersion = *args.TFVersion
+	exportFormat = ExportFormatHcl
+	if args.Format != "" {
+		exportFormat = args.Format
+	}
+	exportProviderAlias = ""
+
+	clients, err := getExportClients("")
+	if err != nil {
+		return err
+	}
+
+	if args.CompartmentName != nil && *args.CompartmentName != "" {
+		var err error
+		args.CompartmentId, err = resolveCompartmentId(clients, args.CompartmentName)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(args.Regions) > 0 {
+		return runRegionsExportCommand(clients, args)
+	}
 
+	return runExportCommand(clients, args)
+}
+
+// Builds the clients used for resource discovery from the provider's environment variables, for the given region
+// if it isn't empty
+func getExportClients(region string) (*OracleClients, error) {
 	r := &schema.Resource{
 		Schema: schemaMap(),
 	}
@@ -128,39 +191,52 @@ func RunExportCommand(args *ExportCommandArgs) error {
 
 	err := readEnvironmentVars(d)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	clients, err := getExportConfig(d)
-	if err != nil {
-		return err
+	if region != "" {
+		if err := d.Set(regionAttrName, region); err != nil {
+			return nil, err
+		}
 	}
 
-	if args.CompartmentName != nil && *args.CompartmentName != "" {
-		var err error
-		args.CompartmentId, err = resolveCompartmentId(clients.(*OracleClients), args.CompartmentName)
-		if err != nil {
-			return err
-		}
+	clients, err := getExportConfig(d)
+	if err != nil {
+		return nil, err
 	}
 
-	return runExportCommand(clients.(*OracleClients), args)
+	return clients.(*OracleClients), nil
 }
 
 // Dedupes possible repeating services from command line and sorts them
 func (args *ExportCommandArgs) finalizeServices() {
+	args.Services = getFinalServices(args.Services)
+}
+
+func getFinalServices(services []string) []string {
 	seenServices := map[string]bool{}
 	finalServices := []string{}
 
-	for _, service := range args.Services {
+	for _, service := range services {
 		if _, seen := seenServices[service]; seen {
 			continue
 		}
 		finalServices = append(finalServices, service)
 		seenServices[service] = true
 	}
-	args.Services = finalServices
-	sort.Strings(args.Services)
+
+	// Resources that are scoped to an availability domain can only be discovered under the availability domains
+	for _, service := range finalServices {
+		if resourceGraph, exists := compartmentResourceGraphs[service]; exists && isAvailabilityDomainScoped(resourceGraph) {
+			if _, seen := seenServices["availability_domain"]; !seen {
+				finalServices = append(finalServices, "availability_domain")
+				seenServices["availability_domain"] = true
+			}
+			break
+		}
+	}
+	sort.Strings(finalServices)
+	return finalServices
 }
 
 // Validate export command arguments and returns nil if there are no issues
@@ -178,7 +254,11 @@ func (args *ExportCommandArgs) validate() error {
 }
 
 func getExportConfig(d *schema.ResourceData) (interface{}, error) {
-	clients := oracleClients
+	// Each export gets its own clients, so that the clients of one region don't replace those of another
+	clients := &OracleClients{configuration: map[string]string{}, clientMap: map[string]*OracleClient{}}
+	for name, client := range oracleClients.clientMap {
+		clients.clientMap[name] = &OracleClient{initClientFn: client.initClientFn}
+	}
 
 	userAgentString := fmt.Sprintf(exportUserAgentFormatter, oci_common.Version(), runtime.Version(), runtime.GOOS, runtime.GOARCH, Version)
 	httpClient := buildHttpClient()
@@ -187,7 +267,6 @@ func getExportConfig(d *schema.ResourceData) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	exportConfigProvider = sdkConfigProvider
 	// beware: global variable `configureClient` set here--used elsewhere outside this execution path
 	configureClient, err := buildConfigureClientFn(sdkConfigProvider, httpClient)
 	if err != nil {
@@ -207,27 +286,71 @@ func getExportConfig(d *schema.ResourceData) (interface{}, error) {
 		return nil, err
 	}
 
+	tenancyId, err := sdkConfigProvider.TenancyOCID()
+	if err != nil {
+		return nil, err
+	}
+	region, err := sdkConfigProvider.Region()
+	if err != nil {
+		return nil, err
+	}
+	clients.configuration[tenancyOcidAttrName] = tenancyId
+	clients.configuration[regionAttrName] = region
+
 	return clients, nil
 }
 
-func runExportCommand(clients *OracleClients, args *ExportCommandArgs) error {
+// The configuration that is generated for a single exported compartment
+type exportCompartment struct {
+	id                  string
+	outputDir           string
+	remoteStateName     string // Name of the terraform_remote_state data source that other compartments use to read this compartment's outputs
+	generateConfigSteps []*GenerateConfigStep
+	referenceMap        map[string]string
+	vars                map[string]string
+	attributeVars       map[string]*attributeVar      // Variables of attributes that could not be discovered or are secrets
+	externalDataSources []*externalDataSource         // Data sources that look up the resources referenced by the compartment that were not exported
+	outputs             map[string]string             // Outputs that are referenced by other compartments, mapped to their value
+	remoteStates        map[string]*exportCompartment // Compartments whose outputs are referenced by this compartment
+}
+
+func newExportCompartment(id string, outputDir string, remoteStateName string) *exportCompartment {
+	return &exportCompartment{
+		id:              id,
+		outputDir:       outputDir,
+		remoteStateName: remoteStateName,
+		attributeVars:   map[string]*attributeVar{},
+		outputs:         map[string]string{},
+		remoteStates:    map[string]*exportCompartment{},
+	}
+}
+
+func runExportCommand(clients *OracleClients, args *ExportCommandArgs) (err error) {
 	if args.OutputDir == nil || *args.OutputDir == "" {
 		return fmt.Errorf("[ERROR] no output directory specified")
 	}
 
-	stateOutputFile := fmt.Sprintf("%s%s%s", *args.OutputDir, string(os.PathSeparator), local.DefaultStateFilename)
-	tmpStateOutputFile := fmt.Sprintf("%s%s%s", *args.OutputDir, string(os.PathSeparator), defaultTmpStateFile)
-
 	log.Printf("Running export command\n")
+	startTime := time.Now()
+	var exportCompartments []*exportCompartment
+	var compartmentTreeErrors []*resourceDiscoveryError
+	defer func() {
+		// The report is written even if the export failed, with the resources that were discovered before the failure
+		if reportErr := writeExportReport(*args.OutputDir, newExportReport(args, exportCompartments, compartmentTreeErrors, startTime, err)); reportErr != nil {
+			log.Printf("[WARN] unable to write %s: %v", exportReportFile, reportErr)
+		}
+	}()
 	if len(args.Services) == 0 {
 		args.Services = compartmentScopeServices
 	}
 
 	args.finalizeServices()
-	generateConfigSteps, err := buildGenerateConfigSteps(args.CompartmentId, args.Services)
-	if err != nil {
-		return err
+
+	if args.CompartmentId == nil || *args.CompartmentId == "" {
+		tenancyId := clients.configuration[tenancyOcidAttrName]
+		args.CompartmentId = &tenancyId
 	}
+
 	// Discover and build a model of all targeted resources
 	matchResourceIds := map[string]bool{}
 	for _, id := range args.IDs {
@@ -236,41 +359,65 @@ func runExportCommand(clients *OracleClients, args *ExportCommandArgs) error {
 		}
 	}
 
-	for _, step := range generateConfigSteps {
-		// Discover all resources in the compartment
-		ociResources, err := findResources(clients, step.root, step.resourceGraph, matchResourceIds)
-		if err != nil {
+	filters, err := parseResourceFilters(args.Filters)
+	if err != nil {
+		return err
+	}
+
+	discoveryPool := newResourceDiscoveryPool(clients, args.Parallelism)
+	discoveryPool.filters = filters
+	discoveryPool.continueOnError = args.ContinueOnError
+	if discoveryPool.naming, err = newResourceNamingStrategy(args.NameTemplate, args.NameMappingFile); err != nil {
+		return err
+	}
+	exportCompartments, compartmentTreeErrors, err = getExportCompartments(discoveryPool, args)
+	if err != nil {
+		return err
+	}
+
+	for idx, compartment := range exportCompartments {
+		// Each compartment is generated as a separate configuration with its own references, variables and names
+		referenceMap = map[string]string{}
+		vars = map[string]string{}
+		resourceNameCount = map[string]int{}
+		discoveryPool.naming.reserveNames()
+
+		// Tenancy-scope resources are only exported once, along with the top compartment
+		services := args.Services
+		if idx > 0 {
+			services = []string{}
+			for _, service := range args.Services {
+				if _, isCompartmentScope := compartmentResourceGraphs[service]; isCompartmentScope {
+					services = append(services, service)
+				}
+			}
+		}
+
+		if compartment.generateConfigSteps, err = buildGenerateConfigSteps(clients, &compartment.id, services); err != nil {
 			return err
 		}
 
-		// Filter out omitted resources from export
-		step.discoveredResources = []*OCIResource{}
-		step.omittedResources = []*OCIResource{}
-		for _, resource := range ociResources {
-			if !resource.omitFromExport {
-				referenceMap[resource.id] = resource.getHclReferenceIdString()
-				step.discoveredResources = append(step.discoveredResources, resource)
-			} else {
-				step.omittedResources = append(step.omittedResources, resource)
-			}
+		if err := discoverCompartmentResources(discoveryPool, compartment.generateConfigSteps, matchResourceIds); err != nil {
+			return err
 		}
+
+		compartment.referenceMap = referenceMap
+		compartment.vars = vars
+		generateAttributeVars(compartment)
 	}
 
-	// Cull any references from the ref map that contain omitted resources
-	// This is to avoid omitted resources from being referenced in generated configs
-	for _, step := range generateConfigSteps {
+	if args.LookupExternalReferences {
+		resolveExternalReferences(clients, exportCompartments)
+	}
 
-		for _, omittedResource := range step.omittedResources {
-			for key, reference := range referenceMap {
-				if strings.Contains(reference, omittedResource.getTerraformReference()) {
-					delete(referenceMap, key)
-				}
-			}
-		}
+	if args.Recursive {
+		// Modules of different compartments are wired together by the root module instead of reading each other's state
+		resolveCrossCompartmentReferences(exportCompartments, args.ModuleLayout != ModuleLayoutCompartment)
 	}
 
+	region := clients.configuration[regionAttrName]
+
 	// Generate HCL configs from all discovered resources
-	allDiscoveredResources := []*OCIResource{}
 	summaryStatements := []string{}
 	defer func() {
 		for _, statement := range summaryStatements {
@@ -278,168 +425,393 @@ func runExportCommand(clients *OracleClients, args *ExportCommandArgs) error {
 		}
 	}()
 
-	for _, step := range generateConfigSteps {
-		configOutputFile := fmt.Sprintf("%s%s%s.tf", *args.OutputDir, string(os.PathSeparator), step.stepName)
-		tmpConfigOutputFile := fmt.Sprintf("%s%s%s.tf.tmp", *args.OutputDir, string(os.PathSeparator), step.stepName)
-
-		file, err := os.OpenFile(tmpConfigOutputFile, os.O_CREATE|os.O_RDWR, 0666)
+	if args.GenerateGraph || args.DiscoverOnly {
+		statements, err := generateDependencyGraphFiles(*args.OutputDir, exportCompartments)
+		summaryStatements = append(summaryStatements, statements...)
 		if err != nil {
 			return err
 		}
+	}
 
-		// Build the HCL config
-		// Note that we still build a TF file even if no resources were discovered for this TF file.
-		// A user may run this command multiple times and may see stale resources if we don't overwrite the file with
-		// an empty one.
-		builder := &strings.Builder{}
-		builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	var statements []string
+	if args.DiscoverOnly {
+		log.Printf("[INFO] skip generating the configuration since only discovery was requested")
+	} else if args.ModuleLayout != ModuleLayoutNone {
+		statements, err = generateModuleConfigs(clients, exportCompartments, args, region)
+	} else {
+		statements, err = generateCompartmentConfigs(clients, exportCompartments, args, region)
+	}
+	summaryStatements = append(summaryStatements, statements...)
+	if err != nil {
+		return err
+	}
 
-		for _, resource := range step.discoveredResources {
-			log.Printf("[INFO] ===> Generating resource '%s'", resource.getTerraformReference())
-			if err := resource.getHCLString(builder, referenceMap); err != nil {
-				_ = file.Close()
-				return err
+	if args.NameMappingFile != "" && !args.DiscoverOnly {
+		if err := discoveryPool.naming.writeNameMapping(args.NameMappingFile, exportCompartments); err != nil {
+			return err
+		}
+		summaryStatements = append(summaryStatements, fmt.Sprintf("Updated the name mapping file '%s'", args.NameMappingFile))
+	}
+
+	if isMissingRequiredAttributes {
+		summaryStatements = append(summaryStatements, "")
+		summaryStatements = append(summaryStatements, missingRequiredAttributeWarning)
+	}
+
+	discoveryErrorStatements := []string{}
+	for _, discoveryError := range compartmentTreeErrors {
+		discoveryErrorStatements = append(discoveryErrorStatements, fmt.Sprintf("- %s: '%s' resources under '%s': %v", compartmentTreeStepName, discoveryError.resourceClass, discoveryError.parent, discoveryError.err))
+	}
+	for _, compartment := range exportCompartments {
+		for _, step := range compartment.generateConfigSteps {
+			for _, discoveryError := range step.discoveryErrors {
+				discoveryErrorStatements = append(discoveryErrorStatements, fmt.Sprintf("- %s: '%s' resources under '%s': %v", step.stepName, discoveryError.resourceClass, discoveryError.parent, discoveryError.err))
 			}
-			allDiscoveredResources = append(allDiscoveredResources, resource)
 		}
+	}
 
-		// Format the HCL config
-		formattedString := hclwrite.Format([]byte(builder.String()))
+	if len(discoveryErrorStatements) > 0 {
+		summaryStatements = append(summaryStatements, "")
+		summaryStatements = append(summaryStatements, "Warning: The following resources were skipped, along with any resources under them, due to errors.")
+		summaryStatements = append(summaryStatements, discoveryErrorStatements...)
+	}
 
-		_, err = file.WriteString(string(formattedString))
+	if len(matchResourceIds) > 0 {
+		missingResourceIds := []string{}
+		for resourceId, found := range matchResourceIds {
+			if !found {
+				missingResourceIds = append(missingResourceIds, resourceId)
+			}
+		}
+
+		if len(missingResourceIds) > 0 {
+			summaryStatements = append(summaryStatements, "")
+			summaryStatements = append(summaryStatements, "Warning: The following resource IDs were not found.")
+			for _, resourceId := range missingResourceIds {
+				summaryStatements = append(summaryStatements, fmt.Sprintf("- %s", resourceId))
+			}
+			return fmt.Errorf("[ERROR] one or more expected resource ids were not found")
+		}
+	}
+
+	if len(discoveryErrorStatements) > 0 {
+		return fmt.Errorf("[ERROR] one or more resources could not be discovered")
+	}
+
+	summaryStatements = append(summaryStatements, "\n=== COMPLETED ===")
+
+	return nil
+}
+
+// Generates a separate configuration for each compartment in its output directory
+func generateCompartmentConfigs(clients *OracleClients, exportCompartments []*exportCompartment, args *ExportCommandArgs, region string) ([]string, error) {
+	summaryStatements := []string{}
+	for idx, compartment := range exportCompartments {
+		// The output directory of the top compartment is expected to exist, but sub-compartment directories are created as needed
+		if idx > 0 {
+			if err := os.MkdirAll(compartment.outputDir, os.ModePerm); err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		compartmentDiscoveredResources, statements, err := generateConfigFiles(compartment.outputDir, compartment.generateConfigSteps, compartment.referenceMap)
+		summaryStatements = append(summaryStatements, statements...)
 		if err != nil {
-			_ = file.Close()
-			return err
+			return summaryStatements, err
 		}
 
-		if fErr := file.Close(); fErr != nil {
-			return fErr
+		compartment.vars["region"] = fmt.Sprintf("\"%s\"", region)
+		if err := generateProviderFile(&compartment.outputDir); err != nil {
+			return summaryStatements, err
 		}
 
-		if err := os.Rename(tmpConfigOutputFile, configOutputFile); err != nil {
-			return err
+		if err := generateVarsFile(compartment.vars, compartment.attributeVars, &compartment.outputDir); err != nil {
+			return summaryStatements, err
 		}
 
-		summaryStatements = append(summaryStatements, fmt.Sprintf("Found %d '%s' resources. Generated under '%s'", len(step.discoveredResources), step.stepName, configOutputFile))
+		if len(compartment.attributeVars) > 0 {
+			if err := generateTfvarsExampleFile(compartment.outputDir, compartment.attributeVars); err != nil {
+				return summaryStatements, err
+			}
+			summaryStatements = append(summaryStatements, getAttributeVarsStatements(compartment.outputDir, compartment.attributeVars)...)
+		}
+
+		if len(compartment.outputs) > 0 {
+			if err := generateOutputsFile(compartment.outputDir, compartment.outputs); err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		if len(compartment.externalDataSources) > 0 {
+			if err := generateExternalReferencesFile(compartment.outputDir, compartment.externalDataSources); err != nil {
+				return summaryStatements, err
+			}
+			summaryStatements = append(summaryStatements, fmt.Sprintf("Generated %d data sources for external references under '%s'", len(compartment.externalDataSources), compartment.outputDir))
+		}
+
+		if len(compartment.remoteStates) > 0 {
+			if err := generateRemoteStateFile(compartment); err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		if args.GenerateState {
+			statements, err := generateStateFile(clients, compartment.outputDir, compartmentDiscoveredResources, nil, args.Parallelism)
+			summaryStatements = append(summaryStatements, statements...)
+			if err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		if args.GenerateImports {
+			statements, err := generateImportScripts(compartment.outputDir, compartment.generateConfigSteps, nil)
+			summaryStatements = append(summaryStatements, statements...)
+			if err != nil {
+				return summaryStatements, err
+			}
+		}
 	}
 
-	if isMissingRequiredAttributes {
-		summaryStatements = append(summaryStatements, "")
-		summaryStatements = append(summaryStatements, missingRequiredAttributeWarning)
+	return summaryStatements, nil
+}
+
+// Returns the compartments to export, starting with the compartment given in the export command arguments.
+// For recursive exports, the configuration of each sub-compartment is generated under its parent compartment's output
+// directory, in a sub-directory named after the sub-compartment. Also returns the errors that caused sub-compartments to
+// be skipped, with the continue on error option, which are not part of the discovery errors of any compartment.
+func getExportCompartments(discoveryPool *resourceDiscoveryPool, args *ExportCommandArgs) ([]*exportCompartment, []*resourceDiscoveryError, error) {
+	rootCompartment := newExportCompartment(*args.CompartmentId, *args.OutputDir, "export")
+	result := []*exportCompartment{rootCompartment}
+	if !args.Recursive {
+		return result, nil, nil
 	}
-	region, err := exportConfigProvider.Region()
+
+	rootCompartmentResource := &OCIResource{
+		compartmentId: *args.CompartmentId,
+		TerraformResource: TerraformResource{
+			id:             *args.CompartmentId,
+			terraformClass: "oci_identity_compartment",
+			terraformName:  "export",
+		},
+	}
+
+	subCompartmentResources, err := discoveryPool.findResources(rootCompartmentResource, compartmentTreeResourceGraph, nil)
+	// The names are only used to find the directory for each sub-compartment, and shouldn't affect the names of the exported resources
+	resourceNameCount = map[string]int{}
+	compartmentTreeErrors := discoveryPool.discoveryErrors
+	discoveryPool.discoveryErrors = nil
 	if err != nil {
-		return err
+		return nil, compartmentTreeErrors, err
 	}
-	vars["region"] = fmt.Sprintf("\"%s\"", region)
 
-	if err := generateProviderFile(args.OutputDir); err != nil {
-		return err
+	// Resources are discovered depth-first, so a parent compartment is always seen before its sub-compartments
+	compartmentsById := map[string]*exportCompartment{rootCompartment.id: rootCompartment}
+	for _, resource := range subCompartmentResources {
+		parent, exists := compartmentsById[resource.parent.id]
+		if !exists {
+			continue
+		}
+
+		dirName := strings.TrimPrefix(resource.terraformName, "export_")
+		outputDir := fmt.Sprintf("%s%s%s", parent.outputDir, string(os.PathSeparator), dirName)
+		remoteStateName := fmt.Sprintf("%s_%s", parent.remoteStateName, dirName)
+
+		compartment := newExportCompartment(resource.id, outputDir, remoteStateName)
+		compartmentsById[compartment.id] = compartment
+		result = append(result, compartment)
 	}
 
-	if err := generateVarsFile(vars, args.OutputDir); err != nil {
-		return err
+	return result, compartmentTreeErrors, nil
+}
+
+// Discovers the resources of all of the given steps and adds references to the discovered resources
+func discoverCompartmentResources(discoveryPool *resourceDiscoveryPool, generateConfigSteps []*GenerateConfigStep, matchResourceIds map[string]bool) error {
+	// Start discovering the resources of all services up front, so that the services are discovered in parallel
+	stepTasks := make([][]*resourceDiscoveryTask, len(generateConfigSteps))
+	for idx, step := range generateConfigSteps {
+		stepTasks[idx] = discoveryPool.discoverChildren(step.root, step.resourceGraph)
 	}
 
-	if args.GenerateState {
-		// Run init and import commands
-		meta := command.Meta{
-			Ui: &cli.BasicUi{
-				Reader:      os.Stdin,
-				Writer:      os.Stdout,
-				ErrorWriter: os.Stderr,
-			},
-			RunningInAutomation: true,
+	discoveryStartTime := time.Now()
+	availabilityDomains := []*OCIResource{}
+	for idx, step := range generateConfigSteps {
+		// Discover all resources in the compartment
+		var ociResources []*OCIResource
+		var err error
+		discoveryErrorCount := len(discoveryPool.discoveryErrors)
+		if isAvailabilityDomainScoped(step.resourceGraph) {
+			// The availability domains are discovered by an earlier step, since steps of compartment-scope services are in sorted order
+			ociResources, err = discoveryPool.findAvailabilityDomainResources(availabilityDomains, step.resourceGraph, matchResourceIds)
+		} else {
+			ociResources, err = discoveryPool.collectResources(step.root, step.resourceGraph, stepTasks[idx], matchResourceIds)
+		}
+		if err != nil {
+			return err
 		}
 
-		initCmd := command.InitCommand{Meta: meta}
-		var initArgs []string
-		if pluginDir := getEnvSettingWithBlankDefault("provider_bin_path"); pluginDir != "" {
-			log.Printf("[INFO] plugin dir: '%s'", pluginDir)
-			initArgs = append(initArgs, fmt.Sprintf("-plugin-dir=%v", pluginDir))
+		for _, resource := range ociResources {
+			if resource.terraformClass == "oci_identity_availability_domain" {
+				availabilityDomains = append(availabilityDomains, resource)
+			}
 		}
-		initArgs = append(initArgs, *args.OutputDir)
-		if errCode := initCmd.Run(initArgs); errCode != 0 {
-			return nil
+		includeInterpolatedResources(ociResources)
+
+		// Filter out omitted resources from export
+		step.discoveredResources = []*OCIResource{}
+		step.omittedResources = []*OCIResource{}
+		for _, resource := range ociResources {
+			if !resource.omitFromExport {
+				addReference(resource.id, resource.getHclReferenceIdString())
+				step.discoveredResources = append(step.discoveredResources, resource)
+			} else {
+				step.omittedResources = append(step.omittedResources, resource)
+			}
 		}
 
-		if err := os.RemoveAll(tmpStateOutputFile); err != nil {
-			log.Printf("[WARN] unable to delete existing tmp state file %s", tmpStateOutputFile)
-			return err
+		// Services are discovered in parallel, so this is the time until all of the service's resources were discovered
+		step.discoveryDuration = time.Since(discoveryStartTime)
+		step.discoveryErrors = discoveryPool.discoveryErrors[discoveryErrorCount:]
+	}
+
+	// Cull any references from the ref map that contain omitted resources
+	// This is to avoid omitted resources from being referenced in generated configs
+	referenceMapLock.Lock()
+	for _, step := range generateConfigSteps {
+
+		for _, omittedResource := range step.omittedResources {
+			for key, reference := range referenceMap {
+				if strings.Contains(reference, omittedResource.getTerraformReference()) {
+					delete(referenceMap, key)
+				}
+			}
+		}
+	}
+	referenceMapLock.Unlock()
+
+	return nil
+}
+
+// Exports any omitted resources that exported resources refer to by interpolation, such as the backend set that is the
+// default of a load balancer listener. Resources referred to only by OCID are left out, and are referred to by OCID.
+func includeInterpolatedResources(resources []*OCIResource) {
+	resourcesByReference := map[string]*OCIResource{}
+	pendingResources := []*OCIResource{}
+	for _, resource := range resources {
+		resourcesByReference[resource.getTerraformReference()] = resource
+		if !resource.omitFromExport {
+			pendingResources = append(pendingResources, resource)
+		}
+	}
+
+	for len(pendingResources) > 0 {
+		resource := pendingResources[0]
+		pendingResources = pendingResources[1:]
+
+		for _, interpolation := range getInterpolationStrings(resource.sourceAttributes) {
+			dependency, exists := resourcesByReference[getInterpolationResourceReference(interpolation.value)]
+			if exists && dependency.omitFromExport {
+				log.Printf("[INFO] exporting '%s' since '%s' refers to it", dependency.getTerraformReference(), resource.getTerraformReference())
+				dependency.omitFromExport = false
+				pendingResources = append(pendingResources, dependency)
+			}
+		}
+	}
+}
+
+// Returns the interpolations in the source attributes of a resource, including those of nested blocks
+func getInterpolationStrings(value interface{}) []InterpolationString {
+	result := []InterpolationString{}
+	switch v := value.(type) {
+	case InterpolationString:
+		result = append(result, v)
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, getInterpolationStrings(item)...)
 		}
+	case map[string]interface{}:
+		for _, item := range v {
+			result = append(result, getInterpolationStrings(item)...)
+		}
+	}
+	return result
+}
 
-		for _, resource := range allDiscoveredResources {
-			log.Printf("[INFO] ===> Importing resource '%s'", resource.getTerraformReference())
+// Generates a config file for each step in the output directory and returns the resources that were generated
+func generateConfigFiles(outputDir string, generateConfigSteps []*GenerateConfigStep, interpolationMap map[string]string) ([]*OCIResource, []string, error) {
+	allDiscoveredResources := []*OCIResource{}
+	summaryStatements := []string{}
+
+	for _, step := range generateConfigSteps {
+		generationStartTime := time.Now()
+		if exportFormat == ExportFormatJson {
+			configOutputFile, err := generateJsonConfigFile(outputDir, step, interpolationMap)
+			if err != nil {
+				return allDiscoveredResources, summaryStatements, err
+			}
+			step.configOutputFile = configOutputFile
+			step.generationDuration = time.Since(generationStartTime)
+			allDiscoveredResources = append(allDiscoveredResources, step.discoveredResources...)
+			summaryStatements = append(summaryStatements, fmt.Sprintf("Found %d '%s' resources. Generated under '%s'", len(step.discoveredResources), step.stepName, configOutputFile))
+			continue
+		}
+
+		configOutputFile := fmt.Sprintf("%s%s%s.tf", outputDir, string(os.PathSeparator), step.stepName)
+		tmpConfigOutputFile := fmt.Sprintf("%s%s%s.tf.tmp", outputDir, string(os.PathSeparator), step.stepName)
+
+		file, err := os.OpenFile(tmpConfigOutputFile, os.O_CREATE|os.O_RDWR, 0666)
+		if err != nil {
+			return allDiscoveredResources, summaryStatements, err
+		}
 
-			resourceDefinition, exists := resourcesMap[resource.terraformClass]
-			if !exists {
-				log.Printf("[INFO] skip importing '%s' since it is not a Terraform OCI resource", resource.getTerraformReference())
-				continue
-			}
+		// Build the HCL config
+		// Note that we still build a TF file even if no resources were discovered for this TF file.
+		// A user may run this command multiple times and may see stale resources if we don't overwrite the file with
+		// an empty one.
+		builder := &strings.Builder{}
+		builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
 
-			if resourceDefinition.Importer == nil {
-				log.Printf("[WARN] unable to import '%s' because import is not supported for '%s'", resource.getTerraformReference(), resource.terraformClass)
-				continue
+		for _, resource := range step.discoveredResources {
+			log.Printf("[INFO] ===> Generating resource '%s'", resource.getTerraformReference())
+			if err := resource.getHCLString(builder, interpolationMap); err != nil {
+				_ = file.Close()
+				return allDiscoveredResources, summaryStatements, err
 			}
+			allDiscoveredResources = append(allDiscoveredResources, resource)
+		}
 
-			importCmd := command.ImportCommand{Meta: meta}
-			importId := resource.importId
-			if len(importId) == 0 {
-				importId = resource.id
-			}
+		// Format the HCL config
+		formattedString := hclwrite.Format([]byte(builder.String()))
 
-			importArgs := []string{
-				fmt.Sprintf("-config=%s", *args.OutputDir),
-				fmt.Sprintf("-state=%s", tmpStateOutputFile),
-				resource.getTerraformReference(),
-				importId,
-			}
-			if errCode := importCmd.Run(importArgs); errCode != 0 {
-				return fmt.Errorf("[ERROR] terraform import command failed for resource '%s' at id '%s'", resource.getTerraformReference(), importId)
-			}
+		_, err = file.WriteString(string(formattedString))
+		if err != nil {
+			_ = file.Close()
+			return allDiscoveredResources, summaryStatements, err
 		}
 
-		if _, err := os.Stat(tmpStateOutputFile); !os.IsNotExist(err) {
-			if err := os.Rename(tmpStateOutputFile, stateOutputFile); err != nil {
-				return err
-			}
+		if fErr := file.Close(); fErr != nil {
+			return allDiscoveredResources, summaryStatements, fErr
 		}
-	}
 
-	if len(matchResourceIds) > 0 {
-		missingResourceIds := []string{}
-		for resourceId, found := range matchResourceIds {
-			if !found {
-				missingResourceIds = append(missingResourceIds, resourceId)
-			}
+		if err := os.Rename(tmpConfigOutputFile, configOutputFile); err != nil {
+			return allDiscoveredResources, summaryStatements, err
 		}
+		step.configOutputFile = configOutputFile
+		step.generationDuration = time.Since(generationStartTime)
 
-		if len(missingResourceIds) > 0 {
-			summaryStatements = append(summaryStatements, "")
-			summaryStatements = append(summaryStatements, "Warning: The following resource IDs were not found.")
-			for _, resourceId := range missingResourceIds {
-				summaryStatements = append(summaryStatements, fmt.Sprintf("- %s", resourceId))
-			}
-			return fmt.Errorf("[ERROR] one or more expected resource ids were not found")
-		}
+		summaryStatements = append(summaryStatements, fmt.Sprintf("Found %d '%s' resources. Generated under '%s'", len(step.discoveredResources), step.stepName, configOutputFile))
 	}
 
-	summaryStatements = append(summaryStatements, "\n=== COMPLETED ===")
-
-	return nil
+	return allDiscoveredResources, summaryStatements, nil
 }
 
-func buildGenerateConfigSteps(compartmentId *string, services []string) ([]*GenerateConfigStep, error) {
+func buildGenerateConfigSteps(clients *OracleClients, compartmentId *string, services []string) ([]*GenerateConfigStep, error) {
 	result := []*GenerateConfigStep{}
 
 	// Note: In case of Instance Principal auth, the TenancyOCID will return
 	// the ocid for the tenancy for the compute instance and not the one for the customer
 	// This needs to be updated in future (if we have customer request) in order to enable
 	// tenancy level resources to be discovered with Instance Principal auth
-	tenancyId, err := exportConfigProvider.TenancyOCID()
-	if err != nil {
-		return nil, err
-	}
+	tenancyId := clients.configuration[tenancyOcidAttrName]
 
 	tenancyResource := &OCIResource{
 		compartmentId: tenancyId,
@@ -458,8 +830,8 @@ func buildGenerateConfigSteps(compartmentId *string, services []string) ([]*Gene
 				stepName:      mode,
 			})
 
-			vars["tenancy_ocid"] = fmt.Sprintf("\"%s\"", tenancyId)
-			referenceMap[tenancyId] = tfHclVersion.getVarHclString("tenancy_ocid")
+			addVar("tenancy_ocid", fmt.Sprintf("\"%s\"", tenancyId))
+			addReference(tenancyId, tfHclVersion.getVarHclString("tenancy_ocid"))
 		}
 	}
 
@@ -483,8 +855,8 @@ func buildGenerateConfigSteps(compartmentId *string, services []string) ([]*Gene
 				stepName:      mode,
 			})
 
-			vars["compartment_ocid"] = fmt.Sprintf("\"%s\"", *compartmentId)
-			referenceMap[*compartmentId] = tfHclVersion.getVarHclString("compartment_ocid")
+			addVar("compartment_ocid", fmt.Sprintf("\"%s\"", *compartmentId))
+			addReference(*compartmentId, tfHclVersion.getVarHclString("compartment_ocid"))
 		}
 	}
 
@@ -492,6 +864,135 @@ func buildGenerateConfigSteps(compartmentId *string, services []string) ([]*Gene
 }
 
 func findResources(clients *OracleClients, root *OCIResource, resourceGraph TerraformResourceGraph, exportableResourceIds map[string]bool) ([]*OCIResource, error) {
+	return newResourceDiscoveryPool(clients, 1).findResources(root, resourceGraph, exportableResourceIds)
+}
+
+var errResourceDiscoveryStopped = fmt.Errorf("[ERROR] resource discovery was stopped due to an earlier error")
+
+// Discovers resources in a resource graph using a bounded number of workers.
+// Workers only run the data source reads (or find override functions) for child associations. Assigning Terraform names,
+// processing the discovered resources and traversing the graph is done by the caller in the same order as a serial
+// discovery would. This keeps the generated names and configurations the same regardless of the parallelism.
+type resourceDiscoveryPool struct {
+	clients     *OracleClients
+	filters     resourceFilters // Filters that discovered resources must pass to be exported
+	naming      *resourceNamingStrategy
+	parallelism int
+	stopped     chan struct{}
+	stopOnce    sync.Once
+
+	// Tasks are queued and run in order by at most parallelism workers. Workers exit once the queue is empty, and are
+	// started again when more tasks are queued.
+	queueMutex sync.Mutex
+	queue      []func()
+	workers    int
+
+	// Set to skip the resources whose discovery failed, rather than stopping all discovery
+	continueOnError bool
+	discoveryErrors []*resourceDiscoveryError // Only collecting resources appends to this, which is done by a single goroutine
+}
+
+// An error that caused the resources of a child association, and any resources under them, to be skipped
+type resourceDiscoveryError struct {
+	parent        string // The Terraform reference of the resource whose children were skipped
+	resourceClass string
+	err           error
+}
+
+// The results of discovering a single child association of a parent resource
+type resourceDiscoveryTask struct {
+	results []*OCIResource
+	err     error
+	done    chan struct{}
+}
+
+func newResourceDiscoveryPool(clients *OracleClients, parallelism int) *resourceDiscoveryPool {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	return &resourceDiscoveryPool{
+		clients:     clients,
+		parallelism: parallelism,
+		stopped:     make(chan struct{}),
+	}
+}
+
+// Queues a task, and starts a worker to run it if fewer than parallelism workers are running
+func (p *resourceDiscoveryPool) enqueue(task func()) {
+	p.queueMutex.Lock()
+	defer p.queueMutex.Unlock()
+
+	p.queue = append(p.queue, task)
+	if p.workers < p.parallelism {
+		p.workers++
+		go p.work()
+	}
+}
+
+// Runs queued tasks until the queue is empty
+func (p *resourceDiscoveryPool) work() {
+	for {
+		p.queueMutex.Lock()
+		if len(p.queue) == 0 {
+			p.workers--
+			p.queueMutex.Unlock()
+			return
+		}
+		task := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.queueMutex.Unlock()
+
+		task()
+	}
+}
+
+func (p *resourceDiscoveryPool) findResources(root *OCIResource, resourceGraph TerraformResourceGraph, exportableResourceIds map[string]bool) ([]*OCIResource, error) {
+	return p.collectResources(root, resourceGraph, p.discoverChildren(root, resourceGraph), exportableResourceIds)
+}
+
+// Prevents any queued discovery from running. Discovery that is already in progress will run to completion.
+func (p *resourceDiscoveryPool) stop() {
+	p.stopOnce.Do(func() { close(p.stopped) })
+}
+
+// Queues the discovery of all child associations of the root resource and returns a task for each association, in the
+// same order as the associations in the resource graph
+func (p *resourceDiscoveryPool) discoverChildren(root *OCIResource, resourceGraph TerraformResourceGraph) []*resourceDiscoveryTask {
+	childResourceTypes := resourceGraph[root.terraformClass]
+	tasks := make([]*resourceDiscoveryTask, len(childResourceTypes))
+
+	for idx := range childResourceTypes {
+		childType := childResourceTypes[idx]
+		task := &resourceDiscoveryTask{done: make(chan struct{})}
+		tasks[idx] = task
+
+		p.enqueue(func() {
+			defer close(task.done)
+
+			select {
+			case <-p.stopped:
+				task.err = errResourceDiscoveryStopped
+				return
+			default:
+			}
+
+			findResourceFn := findResourcesGeneric
+			if childType.findResourcesOverrideFn != nil {
+				findResourceFn = childType.findResourcesOverrideFn
+			}
+			task.results, task.err = findResourceFn(p.clients, &childType, root)
+		})
+	}
+
+	return tasks
+}
+
+// Waits for the discovery tasks of the root resource's child associations and recursively collects all of the
+// resources discovered under them. The discovery of the children of each collected resource is queued before
+// descending into any of them, so that sibling subtrees are discovered in parallel.
+func (p *resourceDiscoveryPool) collectResources(root *OCIResource, resourceGraph TerraformResourceGraph, tasks []*resourceDiscoveryTask, exportableResourceIds map[string]bool) ([]*OCIResource, error) {
 	foundResources := []*OCIResource{}
 
 	childResourceTypes, exists := resourceGraph[root.terraformClass]
@@ -501,26 +1002,35 @@ func findResources(clients *OracleClients, root *OCIResource, resourceGraph Terr
 
 	log.Printf("[INFO] resource discovery: visiting %s\n", root.getTerraformReference())
 
-	for _, childType := range childResourceTypes {
-		findResourceFn := findResourcesGeneric
-		if childType.findResourcesOverrideFn != nil {
-			findResourceFn = childType.findResourcesOverrideFn
-		}
-		results, err := findResourceFn(clients, &childType, root)
-		if err != nil {
-			return foundResources, err
+	for idx, childType := range childResourceTypes {
+		task := tasks[idx]
+		<-task.done
+		if task.err != nil {
+			if p.skipOnError(root, childType, task.err) {
+				continue
+			}
+			p.stop()
+			return foundResources, task.err
 		}
 
+		results := task.results
+		assignTerraformNames(results, p.naming)
+
 		if childType.processDiscoveredResourcesFn != nil {
-			results, err = childType.processDiscoveredResourcesFn(clients, results)
+			var err error
+			results, err = childType.processDiscoveredResourcesFn(p.clients, results)
 			if err != nil {
+				if p.skipOnError(root, childType, err) {
+					continue
+				}
+				p.stop()
 				return foundResources, err
 			}
 		}
 		foundResources = append(foundResources, results...)
 
-		for _, resource := range results {
-			//referenceMap[resource.id] = resource.getHclReferenceIdString()
+		subResourceTasks := make([][]*resourceDiscoveryTask, len(results))
+		for resourceIdx, resource := range results {
 			if exportableResourceIds != nil && len(exportableResourceIds) > 0 {
 				if _, shouldExport := exportableResourceIds[resource.id]; shouldExport {
 					resource.omitFromExport = false
@@ -530,7 +1040,16 @@ func findResources(clients *OracleClients, root *OCIResource, resourceGraph Terr
 				}
 			}
 
-			subResources, err := findResources(clients, resource, resourceGraph, exportableResourceIds)
+			// Resources that are filtered out are still visited, since their children may pass the filters
+			if !resource.omitFromExport && !childType.alwaysExportable && !p.filters.isExportable(resource) {
+				resource.omitFromExport = true
+			}
+
+			subResourceTasks[resourceIdx] = p.discoverChildren(resource, resourceGraph)
+		}
+
+		for resourceIdx, resource := range results {
+			subResources, err := p.collectResources(resource, resourceGraph, subResourceTasks[resourceIdx], exportableResourceIds)
 			if err != nil {
 				return foundResources, err
 			}
@@ -541,7 +1060,330 @@ func findResources(clients *OracleClients, root *OCIResource, resourceGraph Terr
 	return foundResources, nil
 }
 
-func generateVarsFile(vars map[string]string, outputDir *string) error {
+// Runs fn for each index from 0 to count using the workers of the pool, and waits for all of them to finish
+func (p *resourceDiscoveryPool) forEach(count int, fn func(idx int)) {
+	wg := sync.WaitGroup{}
+	wg.Add(count)
+	for idx := 0; idx < count; idx++ {
+		idx := idx
+		p.enqueue(func() {
+			defer wg.Done()
+			fn(idx)
+		})
+	}
+	wg.Wait()
+}
+
+// Records the error of a child association that failed to be discovered, and returns whether its resources should be
+// skipped rather than stopping discovery
+func (p *resourceDiscoveryPool) skipOnError(root *OCIResource, childType TerraformResourceAssociation, err error) bool {
+	if !p.continueOnError {
+		return false
+	}
+
+	log.Printf("[WARN] skipping '%s' resources under '%s' due to error: %v", childType.resourceClass, root.getTerraformReference(), err)
+	p.discoveryErrors = append(p.discoveryErrors, &resourceDiscoveryError{
+		parent:        root.getTerraformReference(),
+		resourceClass: childType.resourceClass,
+		err:           err,
+	})
+	return true
+}
+
+// Discovers the resources of a graph that is scoped to an availability domain under each of the given availability domains
+func (p *resourceDiscoveryPool) findAvailabilityDomainResources(availabilityDomains []*OCIResource, resourceGraph TerraformResourceGraph, exportableResourceIds map[string]bool) ([]*OCIResource, error) {
+	foundResources := []*OCIResource{}
+
+	tasks := make([][]*resourceDiscoveryTask, len(availabilityDomains))
+	for idx, availabilityDomain := range availabilityDomains {
+		tasks[idx] = p.discoverChildren(availabilityDomain, resourceGraph)
+	}
+
+	for idx, availabilityDomain := range availabilityDomains {
+		resources, err := p.collectResources(availabilityDomain, resourceGraph, tasks[idx], exportableResourceIds)
+		if err != nil {
+			return foundResources, err
+		}
+		foundResources = append(foundResources, resources...)
+	}
+
+	return foundResources, nil
+}
+
+// Returns whether the resources of a graph are discovered under availability domains rather than under a compartment
+func isAvailabilityDomainScoped(resourceGraph TerraformResourceGraph) bool {
+	_, hasAvailabilityDomainRoot := resourceGraph["oci_identity_availability_domain"]
+	_, hasCompartmentRoot := resourceGraph["oci_identity_compartment"]
+	return hasAvailabilityDomainRoot && !hasCompartmentRoot
+}
+
+// Assigns Terraform names to discovered resources that don't have one yet.
+// Names are assigned in discovery order rather than by the discovery workers, so that name collisions are always
+// resolved the same way.
+func assignTerraformNames(resources []*OCIResource, naming *resourceNamingStrategy) {
+	for _, resource := range resources {
+		if resource.terraformName != "" {
+			continue
+		}
+
+		var err error
+		if resource.terraformName, err = naming.getTerraformName(resource); err == nil {
+			continue
+		}
+
+		if resource.terraformName, err = generateTerraformNameFromResource(resource.sourceAttributes, resource.terraformNameSchema); err != nil {
+			resource.terraformName = resource.defaultTerraformName
+		}
+	}
+}
+
+// Resolves references between resources that were exported with different compartments.
+// A value that is the ID of a resource exported with another compartment is replaced with an output of that
+// compartment's state, which is read with a terraform_remote_state data source. Any other OCIDs that can't be resolved
+// are replaced with variables, so that they can be set when the configuration is applied elsewhere.
+// If useRemoteState is false, the value is replaced with the reference used by the other compartment instead, for
+// configurations that are generated together (e.g. as modules of the same root module).
+func resolveCrossCompartmentReferences(exportCompartments []*exportCompartment, useRemoteState bool) {
+	exportedResources := map[string]*OCIResource{}
+	exportedResourceCompartments := map[string]*exportCompartment{}
+	for _, compartment := range exportCompartments {
+		for _, step := range compartment.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				if _, exists := exportedResources[resource.id]; !exists {
+					exportedResources[resource.id] = resource
+					exportedResourceCompartments[resource.id] = compartment
+				}
+			}
+		}
+	}
+
+	for _, compartment := range exportCompartments {
+		externalIdCount := map[string]int{}
+		for _, step := range compartment.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				resourceSchema, exists := resourcesMap[resource.terraformClass]
+				if !exists {
+					continue
+				}
+
+				for _, value := range getConfigurableStringValues(resource.sourceAttributes, resourceSchema) {
+					if _, isResolved := compartment.referenceMap[value]; isResolved || value == resource.id {
+						continue
+					}
+
+					if owner, isExported := exportedResourceCompartments[value]; isExported {
+						if owner == compartment {
+							// The reference was culled because it contains an omitted resource
+							continue
+						}
+
+						if ownerReference, exists := owner.referenceMap[value]; exists {
+							if !useRemoteState {
+								compartment.referenceMap[value] = ownerReference
+								continue
+							}
+
+							// The compartments can't read each other's state, since neither could be applied first.
+							// The reference that would close the cycle is replaced with a variable instead.
+							if owner.readsRemoteState(compartment) {
+								log.Printf("[INFO] replacing '%s' in '%s' with a variable, since '%s' reads the state of '%s'", value, resource.getTerraformReference(), owner.outputDir, compartment.outputDir)
+							} else {
+								exportedResource := exportedResources[value]
+								outputName := fmt.Sprintf("%s_%s_id", exportedResource.terraformClass, exportedResource.terraformName)
+								owner.outputs[outputName] = ownerReference
+								compartment.remoteStates[owner.remoteStateName] = owner
+								compartment.referenceMap[value] = tfHclVersion.getRemoteStateOutputHclString(owner.remoteStateName, outputName)
+								continue
+							}
+						}
+					}
+
+					if ocidType := getOcidResourceType(value); ocidType != "" {
+						externalIdCount[ocidType]++
+						varName := fmt.Sprintf("external_%s_%d", ocidType, externalIdCount[ocidType])
+						compartment.vars[varName] = fmt.Sprintf("\"%s\"", value)
+						compartment.referenceMap[value] = tfHclVersion.getVarHclString(varName)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Returns whether a compartment reads the state of another compartment, either directly or through the compartments
+// whose state it reads
+func (compartment *exportCompartment) readsRemoteState(other *exportCompartment) bool {
+	visited := map[*exportCompartment]bool{}
+	pending := []*exportCompartment{compartment}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+		for _, remoteState := range current.remoteStates {
+			if remoteState == other {
+				return true
+			}
+			if !visited[remoteState] {
+				visited[remoteState] = true
+				pending = append(pending, remoteState)
+			}
+		}
+	}
+	return false
+}
+
+// Returns the string values of the attributes that are written to the generated configuration for a resource, in the
+// same order as they are written
+func getConfigurableStringValues(sourceAttributes map[string]interface{}, resourceSchema *schema.Resource) []string {
+	result := []string{}
+
+	sortedKeys := make([]string, 0, len(resourceSchema.Schema))
+	for key := range resourceSchema.Schema {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, tfAttribute := range sortedKeys {
+		tfSchema := resourceSchema.Schema[tfAttribute]
+		if tfSchema.Deprecated != "" || tfSchema.Removed != "" || (!tfSchema.Required && !tfSchema.Optional) {
+			continue
+		}
+
+		switch v := sourceAttributes[tfAttribute].(type) {
+		case string:
+			result = append(result, v)
+		case []interface{}:
+			for _, item := range v {
+				switch itemVal := item.(type) {
+				case string:
+					result = append(result, itemVal)
+				case map[string]interface{}:
+					if nestedResource, ok := tfSchema.Elem.(*schema.Resource); ok {
+						result = append(result, getConfigurableStringValues(itemVal, nestedResource)...)
+					}
+				}
+			}
+		case map[string]interface{}:
+			if nestedResource, ok := tfSchema.Elem.(*schema.Resource); ok {
+				result = append(result, getConfigurableStringValues(v, nestedResource)...)
+				continue
+			}
+
+			for _, mapKey := range getSortedKeys(v) {
+				if mapVal, ok := v[mapKey].(string); ok {
+					result = append(result, mapVal)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// Returns the resource type of an OCID (e.g. 'vcn' for 'ocid1.vcn.oc1.phx.xyz'), or an empty string if the value is not an OCID
+func getOcidResourceType(value string) string {
+	if match := ocidResourceTypeRegex.FindStringSubmatch(value); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// Writes the outputs of a configuration, e.g. the outputs of a compartment that are read by other compartments
+func generateOutputsFile(outputDir string, outputs map[string]string) error {
+	if exportFormat == ExportFormatJson {
+		config := newJsonConfig()
+		for outputName, outputValue := range outputs {
+			config.addBlock("output", map[string]interface{}{"value": getJsonInterpolation(outputValue)}, outputName)
+		}
+		return writeExportJsonFile(outputDir, getExportConfigFileName(outputsFile), config)
+	}
+
+	builder := &strings.Builder{}
+	for _, outputName := range getSortedKeys(convertToObjectMap(outputs)) {
+		builder.WriteString(fmt.Sprintf("output %s {\nvalue = %s\n}\n\n", outputName, outputs[outputName]))
+	}
+
+	return writeExportConfigFile(outputDir, outputsFile, builder.String())
+}
+
+// Writes the terraform_remote_state data sources used to read the outputs of other compartments
+func generateRemoteStateFile(compartment *exportCompartment) error {
+	remoteStateNames := make([]string, 0, len(compartment.remoteStates))
+	for remoteStateName := range compartment.remoteStates {
+		remoteStateNames = append(remoteStateNames, remoteStateName)
+	}
+	sort.Strings(remoteStateNames)
+
+	builder := &strings.Builder{}
+	config := newJsonConfig()
+	for _, remoteStateName := range remoteStateNames {
+		remoteCompartment := compartment.remoteStates[remoteStateName]
+		relativeDir, err := filepath.Rel(compartment.outputDir, remoteCompartment.outputDir)
+		if err != nil {
+			return err
+		}
+
+		statePath := filepath.ToSlash(filepath.Join(relativeDir, local.DefaultStateFilename))
+		builder.WriteString(tfHclVersion.getRemoteStateHclString(remoteStateName, statePath))
+		builder.WriteString("\n")
+		config.addBlock("data", map[string]interface{}{
+			"backend": "local",
+			"config":  map[string]interface{}{"path": statePath},
+		}, "terraform_remote_state", remoteStateName)
+	}
+
+	if exportFormat == ExportFormatJson {
+		return writeExportJsonFile(compartment.outputDir, getExportConfigFileName(remoteStateFile), config)
+	}
+	return writeExportConfigFile(compartment.outputDir, remoteStateFile, builder.String())
+}
+
+// Formats and writes the contents of a config file to the output directory, replacing any existing file
+func writeExportConfigFile(outputDir string, fileName string, contents string) error {
+	tmpOutputFile := fmt.Sprintf("%s%s%s.tmp", outputDir, string(os.PathSeparator), fileName)
+	outputFile := fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName)
+	file, err := os.OpenFile(tmpOutputFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(hclwrite.Format([]byte(contents))); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpOutputFile, outputFile)
+}
+
+// Writes the variables to the vars file of the output directory. Variables of attributes are described by their
+// attributeVar, and optional attributes are given a null default.
+func generateVarsFile(vars map[string]string, attributeVars map[string]*attributeVar, outputDir *string) error {
+	if exportFormat == ExportFormatJson {
+		config := newJsonConfig()
+		varsLock.Lock()
+		for variable, defaultVal := range vars {
+			body := map[string]interface{}{}
+			if defaultVal != "" {
+				body["default"] = getJsonLiteral(defaultVal)
+			}
+			if v, isAttributeVar := attributeVars[variable]; isAttributeVar {
+				body["description"] = v.getDescription()
+				if varType := v.getType(); varType != "" {
+					body["type"] = getJsonLiteral(varType)
+				}
+				if v.optional {
+					body["default"] = nil
+				}
+			}
+			config.addBlock("variable", body, variable)
+		}
+		varsLock.Unlock()
+		return writeExportJsonFile(*outputDir, getExportConfigFileName(varsFile), config)
+	}
+
 	varsTmpFile := fmt.Sprintf("%s%s%s.tmp", *outputDir, string(os.PathSeparator), varsFile)
 	varsOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), varsFile)
 	file, err := os.OpenFile(varsTmpFile, os.O_CREATE|os.O_RDWR, 0666)
@@ -549,7 +1391,33 @@ func generateVarsFile(vars map[string]string, outputDir *string) error {
 		return err
 	}
 
-	for variable, defaultVal := range vars {
+	// Sort the variables so that the generated file is the same across runs
+	varsLock.Lock()
+	defer varsLock.Unlock()
+	variables := make([]string, 0, len(vars))
+	for variable := range vars {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+
+	for _, variable := range variables {
+		defaultVal := vars[variable]
+		if v, isAttributeVar := attributeVars[variable]; isAttributeVar {
+			_, _ = file.WriteString(fmt.Sprintf("# %s\n", v.getDescription()))
+			if v.optional {
+				defaultVal = "null"
+			}
+
+			if varType := v.getType(); varType != "" {
+				_, _ = file.WriteString(fmt.Sprintf("variable %s {\n  type = %s\n", variable, varType))
+				if defaultVal != "" {
+					_, _ = file.WriteString(fmt.Sprintf("  default = %s\n", defaultVal))
+				}
+				_, _ = file.WriteString("}\n")
+				continue
+			}
+		}
+
 		if defaultVal != "" {
 			_, _ = file.WriteString(fmt.Sprintf("variable %s { default = %s }\n", variable, defaultVal))
 		} else {
@@ -569,6 +1437,16 @@ func generateVarsFile(vars map[string]string, outputDir *string) error {
 }
 
 func generateProviderFile(outputDir *string) error {
+	if exportFormat == ExportFormatJson {
+		config := newJsonConfig()
+		body := map[string]interface{}{"region": getJsonInterpolation(tfHclVersion.getVarHclString("region"))}
+		if exportProviderAlias != "" {
+			body["alias"] = exportProviderAlias
+		}
+		config.addBlock("provider", body, "oci")
+		return writeExportJsonFile(*outputDir, getExportConfigFileName(providerFile), config)
+	}
+
 	providerTmpFile := fmt.Sprintf("%s%s%s.tmp", *outputDir, string(os.PathSeparator), providerFile)
 	providerOutputFile := fmt.Sprintf("%s%s%s", *outputDir, string(os.PathSeparator), providerFile)
 	file, err := os.OpenFile(providerTmpFile, os.O_CREATE|os.O_RDWR, 0666)
@@ -576,7 +1454,11 @@ func generateProviderFile(outputDir *string) error {
 		return err
 	}
 
-	_, err = file.WriteString(fmt.Sprintf("provider oci {\n\tregion = %s\n}\n", tfHclVersion.getVarHclString("region")))
+	providerConfig := fmt.Sprintf("provider oci {\n\tregion = %s\n", tfHclVersion.getVarHclString("region"))
+	if exportProviderAlias != "" {
+		providerConfig += fmt.Sprintf("\talias = %q\n", exportProviderAlias)
+	}
+	_, err = file.WriteString(providerConfig + "}\n")
 	if err != nil {
 		_ = file.Close()
 		return err
@@ -599,7 +1481,15 @@ type OCIResource struct {
 	rawResource      interface{}
 	sourceAttributes map[string]interface{}
 	getHclStringFn   func(*strings.Builder, *OCIResource, map[string]string) error
+	addJsonConfigFn  func(jsonConfig, *OCIResource, map[string]string) error
 	parent           *OCIResource
+
+	// Used to assign a Terraform name to resources that are discovered without one
+	terraformNameSchema  map[string]*schema.Schema // Schema of the attributes from which a name may be generated
+	defaultTerraformName string                    // Name to use when no name can be generated from the attributes
+
+	exportErrors  []string        // Errors that didn't fail the export, which are listed in the export report
+	attributeVars []*attributeVar // Variables that replaced attributes which could not be discovered or are secrets
 }
 
 type TerraformResource struct {
@@ -754,8 +1644,8 @@ func getHCLStringFromMap(builder *strings.Builder, sourceAttributes map[string]i
 	return nil
 }
 
-func (ociRes *OCIResource) getHCLString(builder *strings.Builder, interpolationMap map[string]string) error {
-	// Remove any potential cyclical references from the interpolation map
+// Removes any potential cyclical references to the resource from the interpolation map
+func (ociRes *OCIResource) getResourceInterpolationMap(interpolationMap map[string]string) map[string]string {
 	selfReference := ociRes.getTerraformReference()
 	resourceInterpolationMap := map[string]string{}
 	for value, interpolation := range interpolationMap {
@@ -763,6 +1653,11 @@ func (ociRes *OCIResource) getHCLString(builder *strings.Builder, interpolationM
 			resourceInterpolationMap[value] = interpolation
 		}
 	}
+	return resourceInterpolationMap
+}
+
+func (ociRes *OCIResource) getHCLString(builder *strings.Builder, interpolationMap map[string]string) error {
+	resourceInterpolationMap := ociRes.getResourceInterpolationMap(interpolationMap)
 
 	if ociRes.getHclStringFn != nil {
 		return ociRes.getHclStringFn(builder, ociRes, resourceInterpolationMap)
@@ -774,6 +1669,7 @@ func getHclStringFromGenericMap(builder *strings.Builder, ociRes *OCIResource, i
 	resourceSchema := resourcesMap[ociRes.terraformClass]
 
 	builder.WriteString(fmt.Sprintf("resource %s %s {\n", ociRes.terraformClass, ociRes.terraformName))
+	builder.WriteString(getProviderMetaArgumentHclString())
 	if err := getHCLStringFromMap(builder, ociRes.sourceAttributes, resourceSchema, interpolationMap); err != nil {
 		return err
 	}
@@ -938,9 +1834,9 @@ func findResourcesGeneric(clients *OracleClients, tfMeta *TerraformResourceAssoc
 				}
 			}
 
-			if resource.terraformName, err = generateTerraformNameFromResource(resource.sourceAttributes, elemResource.Schema); err != nil {
-				resource.terraformName = fmt.Sprintf("%s_%s_%d", parent.terraformName, tfMeta.resourceAbbreviation, idx+1)
-			}
+			// The Terraform name is assigned when the resource is collected, see assignTerraformNames
+			resource.terraformNameSchema = elemResource.Schema
+			resource.defaultTerraformName = fmt.Sprintf("%s_%s_%d", parent.terraformName, tfMeta.resourceAbbreviation, idx+1)
 
 			results = append(results, resource)
 		}
@@ -951,9 +1847,8 @@ func findResourcesGeneric(clients *OracleClients, tfMeta *TerraformResourceAssoc
 			return results, err
 		}
 
-		if resource.terraformName, err = generateTerraformNameFromResource(resource.sourceAttributes, datasource.Schema); err != nil {
-			resource.terraformName = fmt.Sprintf("%s_%s", parent.terraformName, tfMeta.resourceAbbreviation)
-		}
+		resource.terraformNameSchema = datasource.Schema
+		resource.defaultTerraformName = fmt.Sprintf("%s_%s", parent.terraformName, tfMeta.resourceAbbreviation)
 
 		discoverable := true
 		if state, ok := resource.sourceAttributes["state"]; ok && len(tfMeta.discoverableLifecycleStates) > 0 {
@@ -1007,14 +1902,7 @@ func generateTerraformNameFromResource(resourceAttributes map[string]interface{}
 	for _, nameAttribute := range possibleNameAttributes {
 		if nameSchema, hasNameAttr := resourceSchema[nameAttribute]; hasNameAttr && nameSchema.Type == schema.TypeString {
 			if value, exists := resourceAttributes[nameAttribute]; exists {
-				terraformName := getNormalizedTerraformName(value.(string))
-				if count, resourceNameExists := resourceNameCount[terraformName]; resourceNameExists {
-					resourceNameCount[terraformName] = count + 1
-					terraformName = fmt.Sprintf("%s_%d", terraformName, count)
-				} else {
-					resourceNameCount[terraformName] = 1
-				}
-				return terraformName, nil
+				return getUniqueTerraformName(getNormalizedTerraformName(value.(string))), nil
 			}
 		}
 	}
@@ -1065,16 +1953,17 @@ func generateOciResourceFromResourceData(d *schema.ResourceData, rawResource int
 		resource.getHclStringFn = tfMeta.getHCLStringOverrideFn
 	}
 
+	if tfMeta.addJsonConfigOverrideFn != nil {
+		resource.addJsonConfigFn = tfMeta.addJsonConfigOverrideFn
+	}
+
 	return resource, nil
 }
 
 func resolveCompartmentId(clients *OracleClients, compartmentName *string) (*string, error) {
 	req := oci_identity.ListCompartmentsRequest{}
 
-	rootCompartment, err := exportConfigProvider.TenancyOCID()
-	if err != nil {
-		return nil, err
-	}
+	rootCompartment := clients.configuration[tenancyOcidAttrName]
 	req.CompartmentId = &rootCompartment
 
 	recursiveSearch := true
@@ -1,11 +1,14 @@
 package oci
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -271,9 +274,7 @@ func listTestParents(d *schema.ResourceData, m interface{}) error {
 	results := make([]interface{}, len(parentResources))
 	for i := 0; i < len(parentResources); i++ {
 		id := getTestResourceId("parent", i)
-		resource := parentResources[id]
-		resource["id"] = id
-		results[i] = resource
+		results[i] = parentResources[id]
 	}
 	d.Set("items", results)
 	return nil
@@ -307,7 +308,6 @@ func listTestChildren(d *schema.ResourceData, m interface{}) error {
 	for i := 0; i < len(childrenResources); i++ {
 		id := getTestResourceId("child", i)
 		resource := childrenResources[id]
-		resource["id"] = id
 
 		if !parentIdExists || resource["parent_id"] == parentId {
 			copyResource := map[string]interface{}{}
@@ -358,7 +358,9 @@ func initTestResources() {
 	if parentResources == nil || len(parentResources) != numParentResources {
 		parentResources = make(map[string]map[string]interface{}, numParentResources)
 		for i := 0; i < numParentResources; i++ {
-			parentResources[getTestResourceId("parent", i)] = generateTestResourceFromSchema(i, resourcesMap["oci_test_parent"].Schema)
+			parentResource := generateTestResourceFromSchema(i, resourcesMap["oci_test_parent"].Schema)
+			parentResource["id"] = getTestResourceId("parent", i)
+			parentResources[getTestResourceId("parent", i)] = parentResource
 		}
 	}
 
@@ -372,6 +374,7 @@ func initTestResources() {
 			for j := 0; j < numChildrenResourcesPerParent; j++ {
 				childResource := generateTestResourceFromSchema(i, resourcesMap["oci_test_child"].Schema)
 				childResource["parent_id"] = parentId
+				childResource["id"] = getTestResourceId("child", childCount)
 
 				childrenResources[getTestResourceId("child", childCount)] = childResource
 				childCount++
@@ -498,6 +501,251 @@ func TestUnitRunExportCommand_basic(t *testing.T) {
 	os.RemoveAll(outputDir)
 }
 
+// Test that sub-compartments are exported to their own directories when exporting recursively
+func TestUnitRunExportCommand_recursive(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	subCompartmentOcid := "ocid1.testcompartment.sub"
+	findCompartmentsFn := exportIdentityCompartmentHints.findResourcesOverrideFn
+	defer func() { exportIdentityCompartmentHints.findResourcesOverrideFn = findCompartmentsFn }()
+	exportIdentityCompartmentHints.findResourcesOverrideFn = func(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+		if parent.id != resourceDiscoveryTestCompartmentOcid {
+			return []*OCIResource{}, nil
+		}
+		return []*OCIResource{
+			{
+				compartmentId:    parent.id,
+				sourceAttributes: map[string]interface{}{"name": "sub compartment"},
+				parent:           parent,
+				TerraformResource: TerraformResource{
+					id:             subCompartmentOcid,
+					terraformClass: "oci_identity_compartment",
+					terraformName:  "export_sub-compartment",
+				},
+			},
+		}, nil
+	}
+
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing", "tenancy_testing"},
+		OutputDir:     &outputDir,
+		GenerateState: false,
+		TFVersion:     &tfHclVersion,
+		Recursive:     true,
+	}
+
+	if err = RunExportCommand(args); err != nil {
+		t.Logf("export command failed due to err: %v", err)
+		t.Fail()
+	}
+
+	subCompartmentDir := fmt.Sprintf("%s%ssub-compartment", outputDir, string(os.PathSeparator))
+	for _, file := range []string{"compartment_testing.tf", "provider.tf", "vars.tf"} {
+		if _, err = os.Stat(fmt.Sprintf("%s%s%s", subCompartmentDir, string(os.PathSeparator), file)); os.IsNotExist(err) {
+			t.Logf("no %s file generated for the sub-compartment", file)
+			t.Fail()
+		}
+	}
+
+	// Tenancy-scope resources should only be exported with the top compartment
+	if _, err = os.Stat(fmt.Sprintf("%s%stenancy_testing.tf", subCompartmentDir, string(os.PathSeparator))); !os.IsNotExist(err) {
+		t.Logf("found tenancy_testing.tf for the sub-compartment even though it wasn't expected")
+		t.Fail()
+	}
+
+	subCompartmentVars, err := ioutil.ReadFile(fmt.Sprintf("%s%svars.tf", subCompartmentDir, string(os.PathSeparator)))
+	if err != nil || !strings.Contains(string(subCompartmentVars), subCompartmentOcid) {
+		t.Logf("expected the sub-compartment's vars.tf to contain its compartment OCID")
+		t.Fail()
+	}
+}
+
+// Test that errors while finding sub-compartments are reported, and fail the export, when continuing on error
+func TestUnitRunExportCommand_recursiveContinueOnError(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	subCompartmentOcid := "ocid1.testcompartment.sub"
+	findCompartmentsFn := exportIdentityCompartmentHints.findResourcesOverrideFn
+	defer func() { exportIdentityCompartmentHints.findResourcesOverrideFn = findCompartmentsFn }()
+	exportIdentityCompartmentHints.findResourcesOverrideFn = func(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+		if parent.id != resourceDiscoveryTestCompartmentOcid {
+			return nil, fmt.Errorf("sub-compartment discovery error")
+		}
+		return []*OCIResource{
+			{
+				compartmentId:    parent.id,
+				sourceAttributes: map[string]interface{}{"name": "sub compartment"},
+				parent:           parent,
+				TerraformResource: TerraformResource{
+					id:             subCompartmentOcid,
+					terraformClass: "oci_identity_compartment",
+					terraformName:  "export_sub-compartment",
+				},
+			},
+		}, nil
+	}
+
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId:   &compartmentId,
+		Services:        []string{"compartment_testing"},
+		OutputDir:       &outputDir,
+		TFVersion:       &tfHclVersion,
+		Recursive:       true,
+		ContinueOnError: true,
+	}
+	if err = RunExportCommand(args); err == nil || err.Error() != "[ERROR] one or more resources could not be discovered" {
+		t.Fatalf("expected the export command to fail when sub-compartments are skipped but got '%v'", err)
+	}
+
+	// The compartments that were found are still exported
+	subCompartmentDir := fmt.Sprintf("%s%ssub-compartment", outputDir, string(os.PathSeparator))
+	if _, err = os.Stat(fmt.Sprintf("%s%scompartment_testing.tf", subCompartmentDir, string(os.PathSeparator))); os.IsNotExist(err) {
+		t.Errorf("no compartment_testing.tf file generated for the sub-compartment")
+	}
+
+	contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), exportReportFile))
+	if err != nil {
+		t.Fatalf("no %s file generated", exportReportFile)
+	}
+	report := &exportReport{}
+	if err := json.Unmarshal(contents, report); err != nil {
+		t.Fatalf("unable to read the export report: %v", err)
+	}
+	assert.False(t, report.Succeeded)
+	if assert.Len(t, report.Skipped, 1) {
+		assert.Equal(t, compartmentTreeStepName, report.Skipped[0].Service)
+		assert.Equal(t, "oci_identity_compartment", report.Skipped[0].Class)
+		assert.Equal(t, "oci_identity_compartment.export_sub-compartment", report.Skipped[0].Parent)
+		assert.Equal(t, "sub-compartment discovery error", report.Skipped[0].Error)
+	}
+}
+
+// Test that references to resources exported with other compartments are resolved using remote state outputs
+func TestUnitResolveCrossCompartmentReferences(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	parent := &OCIResource{
+		sourceAttributes: map[string]interface{}{"display_name": "parent"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.parent.abc",
+			terraformClass: "oci_test_parent",
+			terraformName:  "export_parent",
+		},
+	}
+	child := &OCIResource{
+		sourceAttributes: map[string]interface{}{"parent_id": parent.id, "a_string": "ocid1.vcn.oc1.phx.external"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.child.abc",
+			terraformClass: "oci_test_child",
+			terraformName:  "export_child",
+		},
+	}
+
+	topCompartment := newExportCompartment("ocid1.compartment.top", "top", "export")
+	topCompartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{parent}}}
+	topCompartment.referenceMap = map[string]string{parent.id: parent.getHclReferenceIdString()}
+	topCompartment.vars = map[string]string{}
+
+	subCompartment := newExportCompartment("ocid1.compartment.sub", "top/sub", "export_sub")
+	subCompartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{child}}}
+	subCompartment.referenceMap = map[string]string{child.id: child.getHclReferenceIdString()}
+	subCompartment.vars = map[string]string{}
+
+	resolveCrossCompartmentReferences([]*exportCompartment{topCompartment, subCompartment}, true)
+
+	if topCompartment.outputs["oci_test_parent_export_parent_id"] != "oci_test_parent.export_parent.id" {
+		t.Logf("expected an output for the referenced parent but got: %v", topCompartment.outputs)
+		t.Fail()
+	}
+
+	if subCompartment.referenceMap[parent.id] != "data.terraform_remote_state.export.outputs.oci_test_parent_export_parent_id" {
+		t.Logf("expected the parent to be referenced from the remote state but got '%s'", subCompartment.referenceMap[parent.id])
+		t.Fail()
+	}
+
+	if _, exists := subCompartment.remoteStates["export"]; !exists || len(topCompartment.remoteStates) != 0 {
+		t.Logf("expected only the sub-compartment to read the top compartment's remote state")
+		t.Fail()
+	}
+
+	if subCompartment.vars["external_vcn_1"] != "\"ocid1.vcn.oc1.phx.external\"" || subCompartment.referenceMap["ocid1.vcn.oc1.phx.external"] != "var.external_vcn_1" {
+		t.Logf("expected the external OCID to be replaced with a variable but got vars: %v", subCompartment.vars)
+		t.Fail()
+	}
+}
+
+// Test that references between compartments that would make them read each other's state are replaced with variables
+func TestUnitResolveCrossCompartmentReferences_cycle(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	parent := &OCIResource{
+		sourceAttributes: map[string]interface{}{"display_name": "parent", "a_string": "ocid1.child.abc"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.parent.abc",
+			terraformClass: "oci_test_parent",
+			terraformName:  "export_parent",
+		},
+	}
+	child := &OCIResource{
+		sourceAttributes: map[string]interface{}{"parent_id": parent.id},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.child.abc",
+			terraformClass: "oci_test_child",
+			terraformName:  "export_child",
+		},
+	}
+
+	topCompartment := newExportCompartment("ocid1.compartment.top", "top", "export")
+	topCompartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{parent}}}
+	topCompartment.referenceMap = map[string]string{parent.id: parent.getHclReferenceIdString()}
+	topCompartment.vars = map[string]string{}
+
+	subCompartment := newExportCompartment("ocid1.compartment.sub", "top/sub", "export_sub")
+	subCompartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: []*OCIResource{child}}}
+	subCompartment.referenceMap = map[string]string{child.id: child.getHclReferenceIdString()}
+	subCompartment.vars = map[string]string{}
+
+	resolveCrossCompartmentReferences([]*exportCompartment{topCompartment, subCompartment}, true)
+
+	// The parent references the child from the remote state of the sub-compartment
+	assert.Equal(t, "data.terraform_remote_state.export_sub.outputs.oci_test_child_export_child_id", topCompartment.referenceMap[child.id])
+	assert.Equal(t, "oci_test_child.export_child.id", subCompartment.outputs["oci_test_child_export_child_id"])
+	assert.Contains(t, topCompartment.remoteStates, "export_sub")
+
+	// The back reference from the child uses a variable, so that the sub-compartment doesn't read the top compartment's state
+	assert.Empty(t, subCompartment.remoteStates)
+	assert.Empty(t, topCompartment.outputs)
+	assert.Equal(t, "var.external_parent_1", subCompartment.referenceMap[parent.id])
+	assert.Equal(t, "\"ocid1.parent.abc\"", subCompartment.vars["external_parent_1"])
+}
+
 func TestUnitRunExportCommand_error(t *testing.T) {
 	initResourceDiscoveryTests()
 	defer cleanupResourceDiscoveryTests()
@@ -611,6 +859,181 @@ func TestUnitFindResources_restrictedOcids(t *testing.T) {
 	}
 }
 
+// Test that parallel discovery finds the same resources, in the same order and with the same names, as a serial one
+func TestUnitFindResources_parallelism(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	rootResource := getRootCompartmentResource()
+
+	serialResults, err := newResourceDiscoveryPool(nil, 1).findResources(rootResource, compartmentTestingResourceGraph, nil)
+	if err != nil {
+		t.Logf("got error from serial discovery: %v", err)
+		t.Fail()
+	}
+
+	for _, parallelism := range []int{2, 4, 16} {
+		resourceNameCount = map[string]int{}
+		parallelResults, err := newResourceDiscoveryPool(nil, parallelism).findResources(rootResource, compartmentTestingResourceGraph, nil)
+		if err != nil {
+			t.Logf("got error from discovery with parallelism %d: %v", parallelism, err)
+			t.Fail()
+		}
+
+		if len(parallelResults) != len(serialResults) {
+			t.Logf("parallelism %d: got %d results but expected %d results", parallelism, len(parallelResults), len(serialResults))
+			t.Fail()
+			continue
+		}
+
+		for idx, resource := range parallelResults {
+			if resource.id != serialResults[idx].id || resource.getTerraformReference() != serialResults[idx].getTerraformReference() {
+				t.Logf("parallelism %d: result %d is '%s' (%s) but expected '%s' (%s)", parallelism, idx,
+					resource.getTerraformReference(), resource.id, serialResults[idx].getTerraformReference(), serialResults[idx].id)
+				t.Fail()
+			}
+		}
+	}
+}
+
+// Test that a discovery error stops the discovery and is returned
+func TestUnitFindResources_parallelismError(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	rootResource := getRootCompartmentResource()
+
+	exportChildDefinition.findResourcesOverrideFn = func(*OracleClients, *TerraformResourceAssociation, *OCIResource) ([]*OCIResource, error) {
+		return nil, fmt.Errorf("child discovery error")
+	}
+	defer func() { exportChildDefinition.findResourcesOverrideFn = nil }()
+
+	if _, err := newResourceDiscoveryPool(nil, 4).findResources(rootResource, compartmentTestingResourceGraph, nil); err == nil || err.Error() != "child discovery error" {
+		t.Logf("expected the child discovery error but got '%v'", err)
+		t.Fail()
+	}
+}
+
+// Test that queued tasks are run by no more workers than the parallelism, which exit once the queue is empty
+func TestUnitResourceDiscoveryPool_workers(t *testing.T) {
+	discoveryPool := newResourceDiscoveryPool(nil, 3)
+
+	var running, maxRunning int32
+	discoveryPool.forEach(50, func(idx int) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+
+	assert.True(t, atomic.LoadInt32(&maxRunning) <= 3, "expected at most 3 tasks to run at once, but %d did", maxRunning)
+	for start := time.Now(); time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		discoveryPool.queueMutex.Lock()
+		workers := discoveryPool.workers
+		discoveryPool.queueMutex.Unlock()
+		if workers == 0 {
+			return
+		}
+	}
+	t.Errorf("expected the workers to exit once the queue is empty")
+}
+
+// Test that a discovery error only skips the failed resources when continuing on error
+func TestUnitFindResources_continueOnError(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	rootResource := getRootCompartmentResource()
+
+	failedParentId := getTestResourceId("parent", 1)
+	exportChildDefinition.findResourcesOverrideFn = func(clients *OracleClients, association *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+		if parent.id == failedParentId {
+			return nil, fmt.Errorf("child discovery error")
+		}
+		return findResourcesGeneric(clients, association, parent)
+	}
+	defer func() { exportChildDefinition.findResourcesOverrideFn = nil }()
+
+	discoveryPool := newResourceDiscoveryPool(nil, 4)
+	discoveryPool.continueOnError = true
+	results, err := discoveryPool.findResources(rootResource, compartmentTestingResourceGraph, nil)
+	if err != nil {
+		t.Fatalf("unexpected error discovering resources: %v", err)
+	}
+
+	// The children of the failed parent are skipped, while all other resources are still discovered
+	assert.Len(t, results, len(parentResources)+len(childrenResources)-2)
+	for _, resource := range results {
+		assert.NotEqual(t, failedParentId, resource.parent.id)
+	}
+
+	if assert.Len(t, discoveryPool.discoveryErrors, 1) {
+		assert.Equal(t, "oci_test_child", discoveryPool.discoveryErrors[0].resourceClass)
+		assert.Equal(t, "oci_test_parent.export_string1", discoveryPool.discoveryErrors[0].parent)
+		assert.EqualError(t, discoveryPool.discoveryErrors[0].err, "child discovery error")
+	}
+}
+
+// Test that the export command writes the resources that could be discovered, but still fails when continuing on error
+func TestUnitRunExportCommand_continueOnError(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	exportChildDefinition.findResourcesOverrideFn = func(*OracleClients, *TerraformResourceAssociation, *OCIResource) ([]*OCIResource, error) {
+		return nil, fmt.Errorf("child discovery error")
+	}
+	defer func() { exportChildDefinition.findResourcesOverrideFn = nil }()
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		OutputDir:     &outputDir,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err == nil || err.Error() != "child discovery error" {
+		t.Fatalf("expected the export command to stop on the child discovery error but got '%v'", err)
+	}
+
+	args.ContinueOnError = true
+	if err = RunExportCommand(args); err == nil {
+		t.Fatalf("expected the export command to fail when resources are skipped")
+	}
+
+	contents, err := ioutil.ReadFile(fmt.Sprintf("%s%scompartment_testing.tf", outputDir, string(os.PathSeparator)))
+	if err != nil {
+		t.Fatalf("no compartment_testing.tf file generated: %v", err)
+	}
+	assert.Equal(t, len(parentResources), strings.Count(string(contents), "resource oci_test_parent"))
+	assert.NotContains(t, string(contents), "oci_test_child")
+
+	contents, err = ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), exportReportFile))
+	if err != nil {
+		t.Fatalf("no %s file generated", exportReportFile)
+	}
+	report := &exportReport{}
+	if err := json.Unmarshal(contents, report); err != nil {
+		t.Fatalf("unable to read the export report: %v", err)
+	}
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, len(parentResources), len(report.Skipped))
+	for _, skipped := range report.Skipped {
+		assert.Equal(t, "compartment_testing", skipped.Service)
+		assert.Equal(t, "oci_test_child", skipped.Class)
+		assert.Equal(t, "child discovery error", skipped.Error)
+	}
+}
+
 // Test that overriden find function is invoked if a resource has one
 func TestUnitFindResources_overrideFn(t *testing.T) {
 	initResourceDiscoveryTests()
@@ -680,6 +1103,192 @@ func TestUnitFindResources_processResourceFn(t *testing.T) {
 	}
 }
 
+// Test that resources scoped to an availability domain are discovered under each availability domain
+func TestUnitDiscoverCompartmentResources_availabilityDomainScoped(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	referenceMap = map[string]string{}
+	rootResource := getRootCompartmentResource()
+
+	availabilityDomainHints := *exportIdentityAvailabilityDomainHints
+	availabilityDomainHints.findResourcesOverrideFn = func(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+		results := []*OCIResource{}
+		for _, adName := range []string{"AD-1", "AD-2"} {
+			results = append(results, &OCIResource{
+				compartmentId:    parent.compartmentId,
+				sourceAttributes: map[string]interface{}{"name": adName},
+				TerraformResource: TerraformResource{
+					id:             adName,
+					terraformClass: tfMeta.resourceClass,
+					terraformName:  fmt.Sprintf("export_%s", adName),
+				},
+				parent: parent,
+			})
+		}
+		return results, nil
+	}
+
+	scopedHints := *exportParentDefinition
+	scopedHints.findResourcesOverrideFn = func(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+		return []*OCIResource{{
+			compartmentId:    parent.compartmentId,
+			sourceAttributes: map[string]interface{}{"availability_domain": parent.sourceAttributes["name"]},
+			TerraformResource: TerraformResource{
+				id:             fmt.Sprintf("ocid1.parent.%s", parent.id),
+				terraformClass: tfMeta.resourceClass,
+				terraformName:  fmt.Sprintf("export_parent_%s", parent.id),
+			},
+			parent: parent,
+		}}, nil
+	}
+
+	scopedGraph := TerraformResourceGraph{
+		"oci_identity_availability_domain": {
+			{TerraformResourceHints: &scopedHints},
+		},
+	}
+	if !isAvailabilityDomainScoped(scopedGraph) || isAvailabilityDomainScoped(availabilityDomainsGraph) {
+		t.Fatalf("unexpected availability domain scope for the resource graphs")
+	}
+
+	steps := []*GenerateConfigStep{
+		{
+			root:          rootResource,
+			resourceGraph: TerraformResourceGraph{"oci_identity_compartment": {{TerraformResourceHints: &availabilityDomainHints}}},
+			stepName:      "availability_domain",
+		},
+		{
+			root:          rootResource,
+			resourceGraph: scopedGraph,
+			stepName:      "scoped_testing",
+		},
+	}
+
+	if err := discoverCompartmentResources(newResourceDiscoveryPool(nil, 2), steps, nil); err != nil {
+		t.Fatalf("got error from discoverCompartmentResources: %v", err)
+	}
+
+	scopedResources := steps[1].discoveredResources
+	if len(scopedResources) != 2 {
+		t.Fatalf("expected a resource for each availability domain but got %d resources", len(scopedResources))
+	}
+
+	for idx, adName := range []string{"AD-1", "AD-2"} {
+		if scopedResources[idx].parent.id != adName || scopedResources[idx].sourceAttributes["availability_domain"] != adName {
+			t.Logf("expected resource '%s' to be discovered under availability domain '%s'", scopedResources[idx].id, adName)
+			t.Fail()
+		}
+	}
+
+	args := &ExportCommandArgs{Services: []string{"file_storage", "core"}}
+	args.finalizeServices()
+	assert.Equal(t, []string{"availability_domain", "core", "file_storage"}, args.Services)
+}
+
+// Test that DNS records managed by the service are omitted and that the remaining records get composite IDs
+func TestUnitProcessDnsRecords(t *testing.T) {
+	zone := &OCIResource{
+		sourceAttributes: map[string]interface{}{"name": "example.com"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.dns-zone.abc",
+			terraformClass: "oci_dns_zone",
+		},
+	}
+
+	records := []*OCIResource{}
+	for _, record := range []map[string]interface{}{
+		{"domain": "example.com", "rtype": "SOA", "record_hash": "soa", "is_protected": true},
+		{"domain": "example.com", "rtype": "NS", "record_hash": "ns", "is_protected": false},
+		{"domain": "sub.example.com", "rtype": "NS", "record_hash": "delegation", "is_protected": false},
+		{"domain": "www.example.com", "rtype": "A", "record_hash": "www", "is_protected": false},
+	} {
+		records = append(records, &OCIResource{sourceAttributes: record, parent: zone})
+	}
+
+	results, err := processDnsRecords(nil, records)
+	if err != nil {
+		t.Fatalf("got error from processDnsRecords: %v", err)
+	}
+
+	if len(results) != 2 || results[0].sourceAttributes["record_hash"] != "delegation" || results[1].sourceAttributes["record_hash"] != "www" {
+		t.Fatalf("expected only the delegation and www records but got %v", results)
+	}
+
+	for _, record := range results {
+		if record.sourceAttributes["zone_name_or_id"] != zone.id {
+			t.Logf("expected zone_name_or_id to be the zone ID but got '%v'", record.sourceAttributes["zone_name_or_id"])
+			t.Fail()
+		}
+	}
+
+	recordId, err := getDnsRecordId(results[1])
+	if err != nil {
+		t.Fatalf("got error from getDnsRecordId: %v", err)
+	}
+
+	zoneNameOrId, domain, rtype, recordHash, err := parseDnsRecordCompositeId(recordId)
+	if err != nil || zoneNameOrId != zone.id || domain != "www.example.com" || rtype != "A" || recordHash != "www" {
+		t.Logf("composite ID '%s' was not parsed correctly. err: %v", recordId, err)
+		t.Fail()
+	}
+}
+
+// Test that keys and key versions are exported with references to the management endpoint of their vault
+func TestUnitProcessKmsResources(t *testing.T) {
+	referenceMap = map[string]string{}
+	tfHclVersion = &TfHclVersion12{}
+
+	vault := &OCIResource{
+		sourceAttributes: map[string]interface{}{"management_endpoint": "https://abc-management.kms.example.com"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.vault.abc",
+			terraformClass: "oci_kms_vault",
+			terraformName:  "export_vault",
+		},
+	}
+	if _, err := processKmsVaults(nil, []*OCIResource{vault}); err != nil {
+		t.Fatalf("got error from processKmsVaults: %v", err)
+	}
+	assert.Equal(t, "oci_kms_vault.export_vault.management_endpoint", referenceMap["https://abc-management.kms.example.com"])
+
+	key := &OCIResource{
+		sourceAttributes: map[string]interface{}{"management_endpoint": "https://abc-management.kms.example.com"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.key.abc",
+			terraformClass: "oci_kms_key",
+		},
+		parent: vault,
+	}
+	if _, err := processKmsKeys(nil, []*OCIResource{key}); err != nil {
+		t.Fatalf("got error from processKmsKeys: %v", err)
+	}
+	assert.Equal(t, "managementEndpoint/https://abc-management.kms.example.com/keys/ocid1.key.abc", key.importId)
+
+	keyVersions := []*OCIResource{}
+	for _, keyVersion := range []map[string]interface{}{
+		{"key_version_id": "ocid1.keyversion.2", "time_created": "2020-05-02 00:00:00 +0000 UTC"},
+		{"key_version_id": "ocid1.keyversion.1", "time_created": "2020-05-01 00:00:00 +0000 UTC"},
+	} {
+		resource := &OCIResource{sourceAttributes: keyVersion, parent: key}
+		resource.id, _ = getKmsKeyVersionId(resource)
+		keyVersions = append(keyVersions, resource)
+	}
+
+	results, err := processKmsKeyVersions(nil, keyVersions)
+	if err != nil {
+		t.Fatalf("got error from processKmsKeyVersions: %v", err)
+	}
+
+	// The initial version of the key is managed by the key resource
+	if len(results) != 1 {
+		t.Fatalf("expected 1 key version but got %d", len(results))
+	}
+	assert.Equal(t, "keys/ocid1.key.abc/keyVersions/ocid1.keyversion.2", results[0].id)
+	assert.Equal(t, key.id, results[0].sourceAttributes["key_id"])
+	assert.Equal(t, "https://abc-management.kms.example.com", results[0].sourceAttributes["management_endpoint"])
+	assert.Equal(t, "managementEndpoint/https://abc-management.kms.example.com/keys/ocid1.key.abc/keyVersions/ocid1.keyversion.2", results[0].importId)
+}
+
 // Test that Terraform names can be generated from discovered resources
 func TestUnitGenerateTerraformNameFromResource_basic(t *testing.T) {
 	type testCase struct {
@@ -904,6 +1513,190 @@ func TestUnitGetHCLString_interpolationMap(t *testing.T) {
 	}
 }
 
+// Test that alarm destinations and event rule actions reference the exported topics, streams and functions
+func TestUnitRunExportCommand_notificationReferences(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	topicId := "ocid1.onstopic.abc"
+	streamId := "ocid1.stream.abc"
+	functionId := "ocid1.fnfunc.abc"
+	discoveredAttributes := map[string][]map[string]interface{}{
+		"oci_ons_notification_topic": {{"id": topicId, "name": "topic"}},
+		"oci_streaming_stream_pool":  {{"id": "ocid1.streampool.abc", "name": "pool"}},
+		"oci_streaming_stream":       {{"id": streamId, "name": "stream", "stream_pool_id": "ocid1.streampool.abc"}},
+		"oci_functions_application":  {{"id": "ocid1.fnapp.abc", "display_name": "application"}},
+		"oci_functions_function":     {{"id": functionId, "display_name": "function", "application_id": "ocid1.fnapp.abc"}},
+		"oci_monitoring_alarm":       {{"id": "ocid1.alarm.abc", "display_name": "alarm", "destinations": []interface{}{topicId}}},
+		"oci_events_rule": {{
+			"id":           "ocid1.eventrule.abc",
+			"display_name": "rule",
+			"actions": []interface{}{
+				map[string]interface{}{
+					"actions": []interface{}{
+						map[string]interface{}{"action_type": "ONS", "is_enabled": true, "topic_id": topicId},
+						map[string]interface{}{"action_type": "OSS", "is_enabled": true, "stream_id": streamId},
+						map[string]interface{}{"action_type": "FAAS", "is_enabled": true, "function_id": functionId},
+					},
+				},
+			},
+		}},
+	}
+
+	services := []string{"events", "functions", "monitoring", "ons", "streaming"}
+	for _, service := range services {
+		for _, associations := range compartmentResourceGraphs[service] {
+			for _, association := range associations {
+				hints := association.TerraformResourceHints
+				findResourcesFn := hints.findResourcesOverrideFn
+				defer func() { hints.findResourcesOverrideFn = findResourcesFn }()
+				hints.findResourcesOverrideFn = func(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+					results := []*OCIResource{}
+					for _, attributes := range discoveredAttributes[tfMeta.resourceClass] {
+						sourceAttributes := map[string]interface{}{"compartment_id": compartmentId}
+						for key, value := range attributes {
+							sourceAttributes[key] = value
+						}
+						results = append(results, &OCIResource{
+							compartmentId:    compartmentId,
+							sourceAttributes: sourceAttributes,
+							getHclStringFn:   getHclStringFromGenericMap,
+							parent:           parent,
+							TerraformResource: TerraformResource{
+								id:                attributes["id"].(string),
+								terraformClass:    tfMeta.resourceClass,
+								terraformTypeInfo: tfMeta.TerraformResourceHints,
+								terraformName:     fmt.Sprintf("export_%s", tfMeta.resourceAbbreviation),
+							},
+						})
+					}
+					return results, nil
+				}
+			}
+		}
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      services,
+		OutputDir:     &outputDir,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	expectedReferences := map[string]map[string]string{
+		"monitoring": {topicId: "oci_ons_notification_topic.export_notification_topic.id"},
+		"events": {
+			topicId:    "oci_ons_notification_topic.export_notification_topic.id",
+			streamId:   "oci_streaming_stream.export_stream.id",
+			functionId: "oci_functions_function.export_function.id",
+		},
+		"streaming": {"ocid1.streampool.abc": "oci_streaming_stream_pool.export_stream_pool.id"},
+	}
+	for service, references := range expectedReferences {
+		contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s.tf", outputDir, string(os.PathSeparator), service))
+		if err != nil {
+			t.Fatalf("no %s.tf file generated", service)
+		}
+		for ocid, reference := range references {
+			assert.NotContains(t, string(contents), ocid)
+			assert.Contains(t, string(contents), reference)
+		}
+	}
+}
+
+// Test that API deployments reference their exported gateway and function backends, and keep their JWT validation settings
+func TestUnitGetHCLString_apigatewayReferences(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	gateway := &OCIResource{TerraformResource: TerraformResource{id: "ocid1.apigateway.abc", terraformClass: "oci_apigateway_gateway", terraformName: "export_gateway"}}
+	function := &OCIResource{TerraformResource: TerraformResource{id: "ocid1.fnfunc.abc", terraformClass: "oci_functions_function", terraformName: "export_function"}}
+	interpolationMap := map[string]string{}
+	for _, resource := range []*OCIResource{gateway, function} {
+		interpolationMap[resource.id] = resource.getHclReferenceIdString()
+	}
+
+	deployment := &OCIResource{
+		sourceAttributes: map[string]interface{}{
+			"compartment_id": "ocid1.compartment.abc",
+			"gateway_id":     gateway.id,
+			"path_prefix":    "/v1",
+			"specification": []interface{}{
+				map[string]interface{}{
+					"request_policies": []interface{}{
+						map[string]interface{}{
+							"authentication": []interface{}{
+								map[string]interface{}{
+									"type":                      "JWT_AUTHENTICATION",
+									"issuers":                   []interface{}{"https://identity.example.com"},
+									"audiences":                 []interface{}{"api"},
+									"token_header":              "Authorization",
+									"token_auth_scheme":         "Bearer",
+									"max_clock_skew_in_seconds": 10.0,
+									"public_keys": []interface{}{
+										map[string]interface{}{"type": "REMOTE_JWKS", "uri": "https://identity.example.com/jwks", "max_cache_duration_in_hours": 1},
+									},
+									"verify_claims": []interface{}{
+										map[string]interface{}{"key": "scope", "values": []interface{}{"read"}, "is_required": true},
+									},
+								},
+							},
+						},
+					},
+					"routes": []interface{}{
+						map[string]interface{}{
+							"path":    "/hello",
+							"methods": []interface{}{"GET"},
+							"backend": []interface{}{map[string]interface{}{"type": "ORACLE_FUNCTIONS_BACKEND", "function_id": function.id}},
+						},
+						map[string]interface{}{
+							"path":    "/health",
+							"backend": []interface{}{map[string]interface{}{"type": "STOCK_RESPONSE_BACKEND", "status": 200, "body": "ok"}},
+						},
+					},
+				},
+			},
+		},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.apideployment.abc",
+			terraformClass: "oci_apigateway_deployment",
+			terraformName:  "export_deployment",
+		},
+		getHclStringFn: getHclStringFromGenericMap,
+	}
+
+	builder := &strings.Builder{}
+	if err := deployment.getHCLString(builder, interpolationMap); err != nil {
+		t.Fatalf("got error '%v' when trying to get HCL string of '%s'", err, deployment.getTerraformReference())
+	}
+
+	hcl := builder.String()
+	for _, ocid := range []string{gateway.id, function.id} {
+		if strings.Contains(hcl, ocid) || !strings.Contains(hcl, interpolationMap[ocid]) {
+			t.Errorf("expected '%s' to reference '%s' instead of '%s'", deployment.getTerraformReference(), interpolationMap[ocid], ocid)
+		}
+	}
+
+	for _, expected := range []string{`uri\s*=\s*"https://identity.example.com/jwks"`, `token_auth_scheme\s*=\s*"Bearer"`, `max_clock_skew_in_seconds\s*=\s*"?10"?`, `type\s*=\s*"STOCK_RESPONSE_BACKEND"`} {
+		if !regexp.MustCompile(expected).MatchString(hcl) {
+			t.Errorf("expected '%s' in the HCL of '%s':\n%s", expected, deployment.getTerraformReference(), hcl)
+		}
+	}
+}
+
 func TestUnitGetHCLString_tfSyntaxVersion(t *testing.T) {
 	initResourceDiscoveryTests()
 	defer cleanupResourceDiscoveryTests()
@@ -1,17 +1,48 @@
 package oci
 
 import (
+	oci_apigateway "github.com/oracle/oci-go-sdk/apigateway"
 	oci_bds "github.com/oracle/oci-go-sdk/bds"
 	oci_containerengine "github.com/oracle/oci-go-sdk/containerengine"
 	oci_core "github.com/oracle/oci-go-sdk/core"
 	oci_database "github.com/oracle/oci-go-sdk/database"
+	oci_dns "github.com/oracle/oci-go-sdk/dns"
+	oci_events "github.com/oracle/oci-go-sdk/events"
+	oci_file_storage "github.com/oracle/oci-go-sdk/filestorage"
 	oci_functions "github.com/oracle/oci-go-sdk/functions"
 	oci_identity "github.com/oracle/oci-go-sdk/identity"
+	oci_kms "github.com/oracle/oci-go-sdk/keymanagement"
 	oci_limits "github.com/oracle/oci-go-sdk/limits"
 	oci_load_balancer "github.com/oracle/oci-go-sdk/loadbalancer"
+	oci_monitoring "github.com/oracle/oci-go-sdk/monitoring"
+	oci_ons "github.com/oracle/oci-go-sdk/ons"
+	oci_streaming "github.com/oracle/oci-go-sdk/streaming"
 )
 
 // Hints for discovering and exporting this resource to configuration and state files
+var exportApigatewayGatewayHints = &TerraformResourceHints{
+	resourceClass:          "oci_apigateway_gateway",
+	datasourceClass:        "oci_apigateway_gateways",
+	datasourceItemsAttr:    "gateway_collection",
+	resourceAbbreviation:   "gateway",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_apigateway.GatewayLifecycleStateActive),
+	},
+}
+
+// The deployment summaries don't have the specification, so each deployment is read to export its routes and policies
+var exportApigatewayDeploymentHints = &TerraformResourceHints{
+	resourceClass:          "oci_apigateway_deployment",
+	datasourceClass:        "oci_apigateway_deployments",
+	datasourceItemsAttr:    "deployment_collection",
+	resourceAbbreviation:   "deployment",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_apigateway.DeploymentLifecycleStateActive),
+	},
+}
+
 var exportAutoScalingAutoScalingConfigurationHints = &TerraformResourceHints{
 	resourceClass:          "oci_autoscaling_auto_scaling_configuration",
 	datasourceClass:        "oci_autoscaling_auto_scaling_configurations",
@@ -380,6 +411,119 @@ var exportDatabaseDbSystemHints = &TerraformResourceHints{
 	},
 }
 
+var exportDnsRecordHints = &TerraformResourceHints{
+	resourceClass:        "oci_dns_record",
+	datasourceClass:      "oci_dns_records",
+	datasourceItemsAttr:  "records",
+	resourceAbbreviation: "record",
+}
+
+var exportDnsSteeringPolicyAttachmentHints = &TerraformResourceHints{
+	resourceClass:        "oci_dns_steering_policy_attachment",
+	datasourceClass:      "oci_dns_steering_policy_attachments",
+	datasourceItemsAttr:  "steering_policy_attachments",
+	resourceAbbreviation: "steering_policy_attachment",
+	discoverableLifecycleStates: []string{
+		string(oci_dns.SteeringPolicyAttachmentLifecycleStateActive),
+	},
+}
+
+var exportDnsSteeringPolicyHints = &TerraformResourceHints{
+	resourceClass:          "oci_dns_steering_policy",
+	datasourceClass:        "oci_dns_steering_policies",
+	datasourceItemsAttr:    "steering_policies",
+	resourceAbbreviation:   "steering_policy",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_dns.SteeringPolicyLifecycleStateActive),
+	},
+}
+
+var exportDnsTsigKeyHints = &TerraformResourceHints{
+	resourceClass:          "oci_dns_tsig_key",
+	datasourceClass:        "oci_dns_tsig_keys",
+	datasourceItemsAttr:    "tsig_keys",
+	resourceAbbreviation:   "tsig_key",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_dns.TsigKeyLifecycleStateActive),
+	},
+}
+
+var exportDnsZoneHints = &TerraformResourceHints{
+	resourceClass:          "oci_dns_zone",
+	datasourceClass:        "oci_dns_zones",
+	datasourceItemsAttr:    "zones",
+	resourceAbbreviation:   "zone",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_dns.ZoneLifecycleStateActive),
+	},
+}
+
+var exportEventsRuleHints = &TerraformResourceHints{
+	resourceClass:          "oci_events_rule",
+	datasourceClass:        "oci_events_rules",
+	datasourceItemsAttr:    "rules",
+	resourceAbbreviation:   "rule",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_events.RuleLifecycleStateActive),
+	},
+}
+
+var exportFileStorageExportHints = &TerraformResourceHints{
+	resourceClass:          "oci_file_storage_export",
+	datasourceClass:        "oci_file_storage_exports",
+	datasourceItemsAttr:    "exports",
+	resourceAbbreviation:   "export",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_file_storage.ExportLifecycleStateActive),
+	},
+}
+
+var exportFileStorageExportSetHints = &TerraformResourceHints{
+	resourceClass:          "oci_file_storage_export_set",
+	datasourceClass:        "oci_file_storage_export_sets",
+	datasourceItemsAttr:    "export_sets",
+	resourceAbbreviation:   "export_set",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_file_storage.ExportSetLifecycleStateActive),
+	},
+}
+
+var exportFileStorageFileSystemHints = &TerraformResourceHints{
+	resourceClass:        "oci_file_storage_file_system",
+	datasourceClass:      "oci_file_storage_file_systems",
+	datasourceItemsAttr:  "file_systems",
+	resourceAbbreviation: "file_system",
+	discoverableLifecycleStates: []string{
+		string(oci_file_storage.FileSystemLifecycleStateActive),
+	},
+}
+
+var exportFileStorageMountTargetHints = &TerraformResourceHints{
+	resourceClass:        "oci_file_storage_mount_target",
+	datasourceClass:      "oci_file_storage_mount_targets",
+	datasourceItemsAttr:  "mount_targets",
+	resourceAbbreviation: "mount_target",
+	discoverableLifecycleStates: []string{
+		string(oci_file_storage.MountTargetLifecycleStateActive),
+	},
+}
+
+var exportFileStorageSnapshotHints = &TerraformResourceHints{
+	resourceClass:        "oci_file_storage_snapshot",
+	datasourceClass:      "oci_file_storage_snapshots",
+	datasourceItemsAttr:  "snapshots",
+	resourceAbbreviation: "snapshot",
+	discoverableLifecycleStates: []string{
+		string(oci_file_storage.SnapshotLifecycleStateActive),
+	},
+}
+
 var exportFunctionsApplicationHints = &TerraformResourceHints{
 	resourceClass:          "oci_functions_application",
 	datasourceClass:        "oci_functions_applications",
@@ -575,6 +719,38 @@ var exportIdentityTagHints = &TerraformResourceHints{
 	},
 }
 
+var exportKmsKeyHints = &TerraformResourceHints{
+	resourceClass:        "oci_kms_key",
+	datasourceClass:      "oci_kms_keys",
+	datasourceItemsAttr:  "keys",
+	resourceAbbreviation: "key",
+	discoverableLifecycleStates: []string{
+		string(oci_kms.KeyLifecycleStateEnabled),
+		string(oci_kms.KeyLifecycleStateDisabled),
+	},
+}
+
+var exportKmsKeyVersionHints = &TerraformResourceHints{
+	resourceClass:        "oci_kms_key_version",
+	datasourceClass:      "oci_kms_key_versions",
+	datasourceItemsAttr:  "key_versions",
+	resourceAbbreviation: "key_version",
+	discoverableLifecycleStates: []string{
+		string(oci_kms.KeyVersionLifecycleStateEnabled),
+		string(oci_kms.KeyVersionLifecycleStateDisabled),
+	},
+}
+
+var exportKmsVaultHints = &TerraformResourceHints{
+	resourceClass:        "oci_kms_vault",
+	datasourceClass:      "oci_kms_vaults",
+	datasourceItemsAttr:  "vaults",
+	resourceAbbreviation: "vault",
+	discoverableLifecycleStates: []string{
+		string(oci_kms.VaultLifecycleStateActive),
+	},
+}
+
 var exportLimitsQuotaHints = &TerraformResourceHints{
 	resourceClass:          "oci_limits_quota",
 	datasourceClass:        "oci_limits_quotas",
@@ -643,6 +819,17 @@ var exportLoadBalancerRuleSetHints = &TerraformResourceHints{
 	resourceAbbreviation: "rule_set",
 }
 
+var exportMonitoringAlarmHints = &TerraformResourceHints{
+	resourceClass:          "oci_monitoring_alarm",
+	datasourceClass:        "oci_monitoring_alarms",
+	datasourceItemsAttr:    "alarms",
+	resourceAbbreviation:   "alarm",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_monitoring.AlarmLifecycleStateActive),
+	},
+}
+
 var exportObjectStorageBucketHints = &TerraformResourceHints{
 	resourceClass:          "oci_objectstorage_bucket",
 	datasourceClass:        "oci_objectstorage_bucket_summaries",
@@ -656,3 +843,45 @@ var exportObjectStorageNamespaceHints = &TerraformResourceHints{
 	datasourceClass:      "oci_objectstorage_namespace",
 	resourceAbbreviation: "namespace",
 }
+
+var exportOnsNotificationTopicHints = &TerraformResourceHints{
+	resourceClass:        "oci_ons_notification_topic",
+	datasourceClass:      "oci_ons_notification_topics",
+	datasourceItemsAttr:  "notification_topics",
+	resourceAbbreviation: "notification_topic",
+	discoverableLifecycleStates: []string{
+		string(oci_ons.NotificationTopicLifecycleStateActive),
+	},
+}
+
+var exportOnsSubscriptionHints = &TerraformResourceHints{
+	resourceClass:        "oci_ons_subscription",
+	datasourceClass:      "oci_ons_subscriptions",
+	datasourceItemsAttr:  "subscriptions",
+	resourceAbbreviation: "subscription",
+	discoverableLifecycleStates: []string{
+		string(oci_ons.SubscriptionLifecycleStateActive),
+	},
+}
+
+var exportStreamingStreamHints = &TerraformResourceHints{
+	resourceClass:          "oci_streaming_stream",
+	datasourceClass:        "oci_streaming_streams",
+	datasourceItemsAttr:    "streams",
+	resourceAbbreviation:   "stream",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_streaming.StreamLifecycleStateActive),
+	},
+}
+
+var exportStreamingStreamPoolHints = &TerraformResourceHints{
+	resourceClass:          "oci_streaming_stream_pool",
+	datasourceClass:        "oci_streaming_stream_pools",
+	datasourceItemsAttr:    "stream_pools",
+	resourceAbbreviation:   "stream_pool",
+	requireResourceRefresh: true,
+	discoverableLifecycleStates: []string{
+		string(oci_streaming.StreamPoolLifecycleStateActive),
+	},
+}
@@ -0,0 +1,150 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	dependencyGraphDotFile  = "resources.dot"
+	dependencyGraphJsonFile = "resources.json"
+
+	dependencyEdgeContainment = "containment" // The resource was discovered under its parent resource
+	dependencyEdgeReference   = "reference"   // An attribute of the resource refers to the other resource
+)
+
+// The discovered resources and the dependencies between them, across all of the exported compartments
+type dependencyGraph struct {
+	Nodes []*dependencyGraphNode `json:"nodes"`
+	Edges []*dependencyGraphEdge `json:"edges"`
+}
+
+type dependencyGraphNode struct {
+	Id            string `json:"id"`
+	Class         string `json:"class"`
+	Name          string `json:"name"`
+	Service       string `json:"service"`
+	CompartmentId string `json:"compartment_id"`
+}
+
+type dependencyGraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Type      string `json:"type"`
+	Attribute string `json:"attribute,omitempty"` // The attribute of a reference, e.g. 'vcn_id' or 'route_rules.0.network_entity_id'
+}
+
+func newDependencyGraph(exportCompartments []*exportCompartment) *dependencyGraph {
+	graph := &dependencyGraph{Nodes: []*dependencyGraphNode{}, Edges: []*dependencyGraphEdge{}}
+
+	resources := []*OCIResource{}
+	resourceIds := map[string]bool{}
+	resourceReferences := map[string]map[string]string{} // The IDs of the resources in each resource's compartment, by Terraform reference
+	for _, compartment := range exportCompartments {
+		references := map[string]string{}
+		for _, step := range compartment.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				if resource.id == "" || resourceIds[resource.id] {
+					continue
+				}
+				resourceIds[resource.id] = true
+				resources = append(resources, resource)
+				references[resource.getTerraformReference()] = resource.id
+				resourceReferences[resource.id] = references
+
+				graph.Nodes = append(graph.Nodes, &dependencyGraphNode{
+					Id:            resource.id,
+					Class:         resource.terraformClass,
+					Name:          resource.terraformName,
+					Service:       step.stepName,
+					CompartmentId: compartment.id,
+				})
+			}
+		}
+	}
+
+	for _, resource := range resources {
+		if resource.parent != nil && resourceIds[resource.parent.id] {
+			graph.Edges = append(graph.Edges, &dependencyGraphEdge{From: resource.id, To: resource.parent.id, Type: dependencyEdgeContainment})
+		}
+
+		for _, attribute := range getSortedKeys(resource.sourceAttributes) {
+			if attribute == "id" {
+				continue
+			}
+			graph.addReferenceEdges(resource.id, attribute, resource.sourceAttributes[attribute], resourceIds, resourceReferences[resource.id])
+		}
+	}
+
+	return graph
+}
+
+// Adds an edge for each value of the attribute, including nested values, that is the ID of another discovered resource
+// or an interpolation of one of its attributes, e.g. the name of a backend set
+func (graph *dependencyGraph) addReferenceEdges(resourceId string, attribute string, value interface{}, resourceIds map[string]bool, references map[string]string) {
+	switch v := value.(type) {
+	case string:
+		if v != resourceId && resourceIds[v] {
+			graph.Edges = append(graph.Edges, &dependencyGraphEdge{From: resourceId, To: v, Type: dependencyEdgeReference, Attribute: attribute})
+		}
+	case InterpolationString:
+		if referenceId, exists := references[getInterpolationResourceReference(v.value)]; exists && referenceId != resourceId {
+			graph.Edges = append(graph.Edges, &dependencyGraphEdge{From: resourceId, To: referenceId, Type: dependencyEdgeReference, Attribute: attribute})
+		}
+	case []interface{}:
+		for idx, item := range v {
+			graph.addReferenceEdges(resourceId, fmt.Sprintf("%s.%d", attribute, idx), item, resourceIds, references)
+		}
+	case map[string]interface{}:
+		for _, key := range getSortedKeys(v) {
+			graph.addReferenceEdges(resourceId, fmt.Sprintf("%s.%s", attribute, key), v[key], resourceIds, references)
+		}
+	}
+}
+
+// Returns the graph in the DOT language. Containment edges are solid, and reference edges are dashed and labelled
+// with the attribute of the reference.
+func (graph *dependencyGraph) getDotString() string {
+	builder := &strings.Builder{}
+	builder.WriteString("// This graph was generated by terraform-provider-oci\ndigraph resources {\n")
+	builder.WriteString("  rankdir = \"LR\";\n")
+	builder.WriteString("  node [shape = \"box\"];\n")
+
+	for _, node := range graph.Nodes {
+		builder.WriteString(fmt.Sprintf("  %q [label = %q];\n", node.Id, fmt.Sprintf("%s.%s\n%s", node.Class, node.Name, node.Id)))
+	}
+
+	for _, edge := range graph.Edges {
+		if edge.Type == dependencyEdgeReference {
+			builder.WriteString(fmt.Sprintf("  %q -> %q [style = \"dashed\", label = %q];\n", edge.From, edge.To, edge.Attribute))
+		} else {
+			builder.WriteString(fmt.Sprintf("  %q -> %q;\n", edge.From, edge.To))
+		}
+	}
+
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// Writes the dependency graph of the discovered resources to the output directory, in the DOT and JSON formats
+func generateDependencyGraphFiles(outputDir string, exportCompartments []*exportCompartment) ([]string, error) {
+	graph := newDependencyGraph(exportCompartments)
+
+	tmpDotFile := fmt.Sprintf("%s%s%s.tmp", outputDir, string(os.PathSeparator), dependencyGraphDotFile)
+	if err := ioutil.WriteFile(tmpDotFile, []byte(graph.getDotString()), 0666); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmpDotFile, fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), dependencyGraphDotFile)); err != nil {
+		return nil, err
+	}
+
+	if err := writeJsonReportFile(outputDir, dependencyGraphJsonFile, graph); err != nil {
+		return nil, err
+	}
+
+	return []string{
+		fmt.Sprintf("Generated a dependency graph of %d resources and %d dependencies under '%s'", len(graph.Nodes), len(graph.Edges), outputDir),
+	}, nil
+}
@@ -0,0 +1,117 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitNewDependencyGraph(t *testing.T) {
+	vcn := &OCIResource{
+		sourceAttributes:  map[string]interface{}{"id": "ocid1.vcn.1"},
+		TerraformResource: TerraformResource{id: "ocid1.vcn.1", terraformClass: "oci_core_vcn", terraformName: "export_vcn"},
+	}
+	routeTable := &OCIResource{
+		sourceAttributes: map[string]interface{}{
+			"vcn_id":      "ocid1.vcn.1",
+			"route_rules": []interface{}{map[string]interface{}{"network_entity_id": "ocid1.gateway.external"}, map[string]interface{}{"network_entity_id": "ocid1.vcn.1"}},
+		},
+		parent:            vcn,
+		TerraformResource: TerraformResource{id: "ocid1.routetable.1", terraformClass: "oci_core_route_table", terraformName: "export_route_table"},
+	}
+
+	backendSet := &OCIResource{
+		sourceAttributes:  map[string]interface{}{"name": "backend_set"},
+		TerraformResource: TerraformResource{id: "ocid1.backendset.1", terraformClass: "oci_load_balancer_backend_set", terraformName: "export_backend_set"},
+	}
+	listener := &OCIResource{
+		sourceAttributes:  map[string]interface{}{"default_backend_set_name": InterpolationString{value: "oci_load_balancer_backend_set.export_backend_set.name"}},
+		TerraformResource: TerraformResource{id: "ocid1.listener.1", terraformClass: "oci_load_balancer_listener", terraformName: "export_listener"},
+	}
+
+	compartment := newExportCompartment("ocid1.compartment.abc", "", "export")
+	compartment.generateConfigSteps = []*GenerateConfigStep{{stepName: "core", discoveredResources: []*OCIResource{vcn, routeTable}}}
+	graph := newDependencyGraph([]*exportCompartment{compartment})
+
+	assert.Len(t, graph.Nodes, 2)
+	assert.Equal(t, &dependencyGraphNode{Id: "ocid1.routetable.1", Class: "oci_core_route_table", Name: "export_route_table", Service: "core", CompartmentId: "ocid1.compartment.abc"}, graph.Nodes[1])
+
+	// References to resources that were not discovered are left out
+	assert.Equal(t, []*dependencyGraphEdge{
+		{From: "ocid1.routetable.1", To: "ocid1.vcn.1", Type: dependencyEdgeContainment},
+		{From: "ocid1.routetable.1", To: "ocid1.vcn.1", Type: dependencyEdgeReference, Attribute: "route_rules.1.network_entity_id"},
+		{From: "ocid1.routetable.1", To: "ocid1.vcn.1", Type: dependencyEdgeReference, Attribute: "vcn_id"},
+	}, graph.Edges)
+
+	// Interpolations of the attributes of other resources are references too
+	lbCompartment := newExportCompartment("ocid1.compartment.lb", "", "export")
+	lbCompartment.generateConfigSteps = []*GenerateConfigStep{{stepName: "load_balancer", discoveredResources: []*OCIResource{backendSet, listener}}}
+	lbGraph := newDependencyGraph([]*exportCompartment{lbCompartment})
+	assert.Equal(t, []*dependencyGraphEdge{
+		{From: "ocid1.listener.1", To: "ocid1.backendset.1", Type: dependencyEdgeReference, Attribute: "default_backend_set_name"},
+	}, lbGraph.Edges)
+
+	dot := graph.getDotString()
+	assert.Contains(t, dot, "\"ocid1.vcn.1\" [label = \"oci_core_vcn.export_vcn\\nocid1.vcn.1\"];\n")
+	assert.Contains(t, dot, "\"ocid1.routetable.1\" -> \"ocid1.vcn.1\";\n")
+	assert.Contains(t, dot, "\"ocid1.routetable.1\" -> \"ocid1.vcn.1\" [style = \"dashed\", label = \"vcn_id\"];\n")
+}
+
+// Test that the discover only mode writes the dependency graph without any configuration
+func TestUnitRunExportCommand_discoverOnly(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		OutputDir:     &outputDir,
+		DiscoverOnly:  true,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	for _, fileName := range []string{"compartment_testing.tf", providerFile, varsFile} {
+		if _, err = os.Stat(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName)); !os.IsNotExist(err) {
+			t.Errorf("found %s even though no configuration was expected", fileName)
+		}
+	}
+
+	if _, err = os.Stat(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), dependencyGraphDotFile)); os.IsNotExist(err) {
+		t.Errorf("no %s file generated", dependencyGraphDotFile)
+	}
+
+	contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), dependencyGraphJsonFile))
+	if err != nil {
+		t.Fatalf("no %s file generated", dependencyGraphJsonFile)
+	}
+
+	graph := &dependencyGraph{}
+	if err := json.Unmarshal(contents, graph); err != nil {
+		t.Fatalf("unable to read the dependency graph: %v", err)
+	}
+	assert.Len(t, graph.Nodes, len(parentResources)+len(childrenResources))
+
+	edgeCounts := map[string]int{}
+	for _, edge := range graph.Edges {
+		edgeCounts[edge.Type]++
+	}
+	// Each child is contained by its parent, and refers to it by its parent_id
+	assert.Equal(t, map[string]int{dependencyEdgeContainment: len(childrenResources), dependencyEdgeReference: len(childrenResources)}, edgeCounts)
+}
@@ -0,0 +1,495 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const (
+	driftReportFile     = "drift_report.json"
+	driftReportTextFile = "drift_report.txt"
+)
+
+type DriftCommandArgs struct {
+	CompartmentId   *string
+	CompartmentName *string
+	Services        []string
+	StateFile       *string
+	OutputDir       *string
+	Parallelism     int
+}
+
+// The subset of a Terraform v4 state file that is needed to compare the state with the discovered resources
+type terraformState struct {
+	Version   int                      `json:"version"`
+	Resources []terraformStateResource `json:"resources"`
+}
+
+type terraformStateResource struct {
+	Module    string                           `json:"module,omitempty"`
+	Mode      string                           `json:"mode"`
+	Type      string                           `json:"type"`
+	Name      string                           `json:"name"`
+	Instances []terraformStateResourceInstance `json:"instances"`
+}
+
+type terraformStateResourceInstance struct {
+	IndexKey   interface{}            `json:"index_key,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// The differences between a state file and the resources that were discovered in the compartment
+type driftReport struct {
+	CompartmentId string                  `json:"compartment_id"`
+	StateFile     string                  `json:"state_file"`
+	Unmanaged     []*driftResource        `json:"unmanaged"` // Resources that exist but are not in the state
+	Missing       []*driftResource        `json:"missing"`   // Resources in the state that no longer exist
+	Changed       []*driftChangedResource `json:"changed"`   // Resources whose live attributes differ from the state
+}
+
+type driftResource struct {
+	Type    string `json:"type"`
+	Id      string `json:"id"`
+	Address string `json:"address,omitempty"` // The address of the resource in the state, if it is managed
+}
+
+type driftChangedResource struct {
+	driftResource
+	Attributes []*driftAttribute `json:"attributes"`
+}
+
+type driftAttribute struct {
+	Name  string      `json:"name"`
+	State interface{} `json:"state"`
+	Live  interface{} `json:"live"`
+}
+
+func (report *driftReport) hasDrift() bool {
+	return len(report.Unmanaged) > 0 || len(report.Missing) > 0 || len(report.Changed) > 0
+}
+
+func RunDriftCommand(args *DriftCommandArgs) error {
+	resourcesMap = ResourcesMap()
+	datasourcesMap = DataSourcesMap()
+	// The configuration isn't generated, but discovery builds references using the HCL syntax
+	tfHclVersion = &TfHclVersion12{}
+
+	clients, err := getExportClients("")
+	if err != nil {
+		return err
+	}
+
+	if args.CompartmentName != nil && *args.CompartmentName != "" {
+		var err error
+		args.CompartmentId, err = resolveCompartmentId(clients, args.CompartmentName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return runDriftCommand(clients, args)
+}
+
+func runDriftCommand(clients *OracleClients, args *DriftCommandArgs) error {
+	if args.StateFile == nil || *args.StateFile == "" {
+		return fmt.Errorf("[ERROR] no state file specified")
+	}
+
+	state, err := readTerraformState(*args.StateFile)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Running drift command\n")
+	if len(args.Services) == 0 {
+		args.Services = compartmentScopeServices
+	}
+	args.Services = getFinalServices(args.Services)
+
+	if args.CompartmentId == nil || *args.CompartmentId == "" {
+		tenancyId := clients.configuration[tenancyOcidAttrName]
+		args.CompartmentId = &tenancyId
+	}
+
+	referenceMap = map[string]string{}
+	vars = map[string]string{}
+	resourceNameCount = map[string]int{}
+
+	generateConfigSteps, err := buildGenerateConfigSteps(clients, args.CompartmentId, args.Services)
+	if err != nil {
+		return err
+	}
+
+	discoveryPool := newResourceDiscoveryPool(clients, args.Parallelism)
+	if err := discoverCompartmentResources(discoveryPool, generateConfigSteps, map[string]bool{}); err != nil {
+		return err
+	}
+
+	report := buildDriftReport(state, generateConfigSteps)
+	report.CompartmentId = *args.CompartmentId
+	report.StateFile = *args.StateFile
+
+	text := report.getText()
+	if report.hasDrift() {
+		color.Yellow(text)
+	} else {
+		color.Green(text)
+	}
+
+	if args.OutputDir != nil && *args.OutputDir != "" {
+		if err := writeDriftReportFiles(*args.OutputDir, report, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readTerraformState(stateFile string) (*terraformState, error) {
+	contents, err := ioutil.ReadFile(stateFile)
+	if err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read state file %s: %v", stateFile, err)
+	}
+
+	state := &terraformState{}
+	if err := json.Unmarshal(contents, state); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to parse state file %s: %v", stateFile, err)
+	}
+
+	if state.Version != 4 {
+		return nil, fmt.Errorf("[ERROR] unsupported state file version %d, only state files written by Terraform v0.12 and later are supported", state.Version)
+	}
+
+	return state, nil
+}
+
+// Returns the address of a resource instance in the state, e.g. 'module.network.oci_core_vcn.vcn[0]'
+func (resource *terraformStateResource) getAddress(instance *terraformStateResourceInstance) string {
+	address := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+	if resource.Module != "" {
+		address = fmt.Sprintf("%s.%s", resource.Module, address)
+	}
+
+	switch key := instance.IndexKey.(type) {
+	case string:
+		address = fmt.Sprintf("%s[\"%s\"]", address, key)
+	case float64:
+		address = fmt.Sprintf("%s[%d]", address, int(key))
+	}
+	return address
+}
+
+// Compares the managed resources in the state with the resources discovered by the steps. Only resources of the
+// classes that the steps can discover, in the compartments that the steps discover, are compared.
+func buildDriftReport(state *terraformState, generateConfigSteps []*GenerateConfigStep) *driftReport {
+	report := &driftReport{
+		Unmanaged: []*driftResource{},
+		Missing:   []*driftResource{},
+		Changed:   []*driftChangedResource{},
+	}
+
+	discoverableClasses := map[string]bool{}
+	compartmentIds := map[string]bool{}
+	discoveredResources := map[string]*OCIResource{}
+	for _, step := range generateConfigSteps {
+		compartmentIds[step.root.compartmentId] = true
+		for _, associations := range step.resourceGraph {
+			for _, association := range associations {
+				discoverableClasses[association.resourceClass] = true
+			}
+		}
+
+		for _, resource := range step.discoveredResources {
+			discoveredResources[resource.id] = resource
+		}
+	}
+
+	matchedResources := map[*OCIResource]bool{}
+	for _, stateResource := range state.Resources {
+		if stateResource.Mode != "managed" || !discoverableClasses[stateResource.Type] {
+			continue
+		}
+
+		for idx := range stateResource.Instances {
+			instance := &stateResource.Instances[idx]
+			compartmentId, hasCompartment := instance.Attributes["compartment_id"].(string)
+			if hasCompartment && !compartmentIds[compartmentId] {
+				continue
+			}
+
+			id, _ := instance.Attributes["id"].(string)
+			driftRes := driftResource{
+				Type:    stateResource.Type,
+				Id:      id,
+				Address: stateResource.getAddress(instance),
+			}
+
+			resource := findDriftResource(discoveredResources, stateResource.Type, id)
+			if resource == nil {
+				// Resources without a compartment, such as DNS records, may belong to a parent in another compartment
+				if hasCompartment || hasDiscoveredDriftParent(generateConfigSteps, stateResource.Type, instance.Attributes) {
+					report.Missing = append(report.Missing, &driftRes)
+				}
+				continue
+			}
+			matchedResources[resource] = true
+
+			if attributes := getDriftAttributes(resource, instance.Attributes); len(attributes) > 0 {
+				report.Changed = append(report.Changed, &driftChangedResource{driftResource: driftRes, Attributes: attributes})
+			}
+		}
+	}
+
+	for _, step := range generateConfigSteps {
+		for _, resource := range step.discoveredResources {
+			// Data sources such as availability domains are discovered to find other resources, but aren't managed
+			if _, isResource := resourcesMap[resource.terraformClass]; !isResource || matchedResources[resource] {
+				continue
+			}
+			report.Unmanaged = append(report.Unmanaged, &driftResource{Type: resource.terraformClass, Id: resource.id})
+		}
+	}
+
+	return report
+}
+
+// Returns whether the steps discovered the parent of a resource in the state, e.g. the zone of a DNS record. The parent
+// is identified by the attributes of the resource that the data source of the resource is queried with.
+func hasDiscoveredDriftParent(generateConfigSteps []*GenerateConfigStep, terraformClass string, stateAttributes map[string]interface{}) bool {
+	for _, step := range generateConfigSteps {
+		for parentClass, associations := range step.resourceGraph {
+			if parentClass == step.root.terraformClass {
+				continue
+			}
+
+			for _, association := range associations {
+				if association.resourceClass != terraformClass || len(association.datasourceQueryParams) == 0 {
+					continue
+				}
+
+				for _, resources := range [][]*OCIResource{step.discoveredResources, step.omittedResources} {
+					for _, parent := range resources {
+						if parent.terraformClass == parentClass && isDriftParent(parent, association.datasourceQueryParams, stateAttributes) {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func isDriftParent(parent *OCIResource, datasourceQueryParams map[string]string, stateAttributes map[string]interface{}) bool {
+	for attribute, parentAttribute := range datasourceQueryParams {
+		// Quoted values are literals, rather than attributes of the parent
+		if strings.HasPrefix(parentAttribute, "'") {
+			continue
+		}
+
+		parentValue := parent.id
+		if parentAttribute != "id" {
+			parentValue, _ = parent.sourceAttributes[parentAttribute].(string)
+		}
+		if value, _ := stateAttributes[attribute].(string); value == "" || value != parentValue {
+			return false
+		}
+	}
+	return true
+}
+
+// Finds the discovered resource for the ID of a resource in the state, which may either be the OCID or the import ID
+func findDriftResource(discoveredResources map[string]*OCIResource, terraformClass string, id string) *OCIResource {
+	if resource, exists := discoveredResources[id]; exists && resource.terraformClass == terraformClass {
+		return resource
+	}
+
+	for _, resource := range discoveredResources {
+		if resource.terraformClass == terraformClass && resource.importId == id {
+			return resource
+		}
+	}
+	return nil
+}
+
+// Returns the configurable attributes of a discovered resource whose values differ from the state.
+// Attributes that were not discovered, or that are empty in either the state or the discovered resource, are not compared
+// since discovery can't distinguish them from attributes that are not set.
+func getDriftAttributes(resource *OCIResource, stateAttributes map[string]interface{}) []*driftAttribute {
+	resourceSchema, exists := resourcesMap[resource.terraformClass]
+	if !exists {
+		return nil
+	}
+
+	tfAttributes := []string{}
+	for tfAttribute := range resourceSchema.Schema {
+		tfAttributes = append(tfAttributes, tfAttribute)
+	}
+	sort.Strings(tfAttributes)
+
+	result := []*driftAttribute{}
+	for _, tfAttribute := range tfAttributes {
+		tfSchema := resourceSchema.Schema[tfAttribute]
+		if !isConfigurableAttribute(tfSchema) {
+			continue
+		}
+
+		if _, isInterpolation := resource.sourceAttributes[tfAttribute].(InterpolationString); isInterpolation {
+			continue
+		}
+
+		liveValue := normalizeDriftValue(getDriftJsonValue(resource.sourceAttributes[tfAttribute]), tfSchema)
+		stateValue := normalizeDriftValue(stateAttributes[tfAttribute], tfSchema)
+		if liveValue == nil || stateValue == nil || reflect.DeepEqual(liveValue, stateValue) {
+			continue
+		}
+
+		result = append(result, &driftAttribute{Name: tfAttribute, State: stateValue, Live: liveValue})
+	}
+	return result
+}
+
+func isConfigurableAttribute(tfSchema *schema.Schema) bool {
+	return tfSchema.Deprecated == "" && tfSchema.Removed == "" && (tfSchema.Required || tfSchema.Optional)
+}
+
+// Converts a discovered value to the types used when reading the state file, e.g. all numbers are float64
+func getDriftJsonValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("[WARN] unable to convert discovered value '%v': %v\n", value, err)
+		return nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(contents, &result); err != nil {
+		return nil
+	}
+	return result
+}
+
+// Removes empty values and sorts the elements of sets so that the state and discovered values can be compared.
+// Returns nil if the value is empty.
+func normalizeDriftValue(value interface{}, tfSchema *schema.Schema) interface{} {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+	case map[string]interface{}:
+		if _, ok := tfSchema.Elem.(*schema.Resource); ok && tfSchema.Type != schema.TypeMap {
+			// A single nested block may be discovered as a map rather than a list
+			return normalizeDriftValue([]interface{}{v}, tfSchema)
+		}
+
+		result := map[string]interface{}{}
+		for key, item := range v {
+			if normalized := normalizeDriftValue(item, &schema.Schema{Type: schema.TypeString}); normalized != nil {
+				result[key] = normalized
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+	case []interface{}:
+		items := []interface{}{}
+		for _, item := range v {
+			var normalized interface{}
+			if nestedResource, ok := tfSchema.Elem.(*schema.Resource); ok {
+				if block, ok := item.(map[string]interface{}); ok {
+					normalized = normalizeDriftBlock(block, nestedResource)
+				}
+			} else {
+				normalized = normalizeDriftValue(item, &schema.Schema{Type: schema.TypeString})
+			}
+
+			if normalized != nil {
+				items = append(items, normalized)
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		if tfSchema.Type == schema.TypeSet {
+			sort.Slice(items, func(i, j int) bool {
+				return fmt.Sprintf("%v", items[i]) < fmt.Sprintf("%v", items[j])
+			})
+		}
+		return items
+	}
+	return value
+}
+
+func normalizeDriftBlock(block map[string]interface{}, resourceSchema *schema.Resource) interface{} {
+	result := map[string]interface{}{}
+	for tfAttribute, tfSchema := range resourceSchema.Schema {
+		if !isConfigurableAttribute(tfSchema) {
+			continue
+		}
+
+		if normalized := normalizeDriftValue(block[tfAttribute], tfSchema); normalized != nil {
+			result[tfAttribute] = normalized
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func (report *driftReport) getText() string {
+	builder := &strings.Builder{}
+	builder.WriteString(fmt.Sprintf("Drift between state file '%s' and compartment '%s'\n", report.StateFile, report.CompartmentId))
+
+	builder.WriteString(fmt.Sprintf("\nUnmanaged resources, that exist but are not in the state: %d\n", len(report.Unmanaged)))
+	for _, resource := range report.Unmanaged {
+		builder.WriteString(fmt.Sprintf("- %s %s\n", resource.Type, resource.Id))
+	}
+
+	builder.WriteString(fmt.Sprintf("\nMissing resources, that are in the state but no longer exist: %d\n", len(report.Missing)))
+	for _, resource := range report.Missing {
+		builder.WriteString(fmt.Sprintf("- %s (%s)\n", resource.Address, resource.Id))
+	}
+
+	builder.WriteString(fmt.Sprintf("\nChanged resources, whose attributes differ from the state: %d\n", len(report.Changed)))
+	for _, resource := range report.Changed {
+		builder.WriteString(fmt.Sprintf("- %s (%s)\n", resource.Address, resource.Id))
+		for _, attribute := range resource.Attributes {
+			builder.WriteString(fmt.Sprintf("    %s: %s => %s\n", attribute.Name, getDriftValueString(attribute.State), getDriftValueString(attribute.Live)))
+		}
+	}
+
+	return builder.String()
+}
+
+func getDriftValueString(value interface{}) string {
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(contents)
+}
+
+// Writes the report as text and JSON files to the output directory
+func writeDriftReportFiles(outputDir string, report *driftReport, text string) error {
+	if err := writeJsonReportFile(outputDir, driftReportFile, report); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), driftReportTextFile), []byte(text), 0666)
+}
@@ -0,0 +1,167 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the drift command reports the differences between a state file and the discovered resources
+func TestUnitRunDriftCommand(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	state := &terraformState{
+		Version: 4,
+		Resources: []terraformStateResource{
+			{
+				Mode: "managed", Type: "oci_test_parent", Name: "changed",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": getTestResourceId("parent", 0), "display_name": "old_name", "a_int": 0}},
+				},
+			},
+			{
+				Mode: "managed", Type: "oci_test_parent", Name: "deleted",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": getTestResourceId("parent", 99), "compartment_id": compartmentId}},
+				},
+			},
+			{
+				Mode: "managed", Type: "oci_test_parent", Name: "other_compartment",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": getTestResourceId("parent", 98), "compartment_id": "ocid1.compartment.other"}},
+				},
+			},
+			{
+				Mode: "data", Type: "oci_test_parents", Name: "parents",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": "parents"}},
+				},
+			},
+			{
+				Module: "module.children", Mode: "managed", Type: "oci_test_child", Name: "child",
+				Instances: []terraformStateResourceInstance{
+					{IndexKey: 0, Attributes: map[string]interface{}{"id": getTestResourceId("child", 0)}},
+				},
+			},
+			{
+				Mode: "managed", Type: "oci_test_child", Name: "deleted_child",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": getTestResourceId("child", 99), "parent_id": getTestResourceId("parent", 0)}},
+				},
+			},
+			{
+				// Children don't have a compartment in this state, so the children of parents in other compartments aren't compared
+				Mode: "managed", Type: "oci_test_child", Name: "other_compartment_child",
+				Instances: []terraformStateResourceInstance{
+					{Attributes: map[string]interface{}{"id": getTestResourceId("child", 98), "parent_id": getTestResourceId("parent", 98)}},
+				},
+			},
+		},
+	}
+	stateFile := fmt.Sprintf("%s%sterraform.tfstate", outputDir, string(os.PathSeparator))
+	contents, _ := json.Marshal(state)
+	if err := ioutil.WriteFile(stateFile, contents, 0666); err != nil {
+		t.Fatalf("unable to write state file: %v", err)
+	}
+
+	args := &DriftCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		StateFile:     &stateFile,
+		OutputDir:     &outputDir,
+		Parallelism:   1,
+	}
+	if err := RunDriftCommand(args); err != nil {
+		t.Fatalf("drift command failed due to err: %v", err)
+	}
+
+	if _, err := os.Stat(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), driftReportTextFile)); os.IsNotExist(err) {
+		t.Logf("no %s file generated", driftReportTextFile)
+		t.Fail()
+	}
+
+	reportContents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), driftReportFile))
+	if err != nil {
+		t.Fatalf("no %s file generated", driftReportFile)
+	}
+	report := &driftReport{}
+	if err := json.Unmarshal(reportContents, report); err != nil {
+		t.Fatalf("unable to read the drift report: %v", err)
+	}
+
+	if assert.Len(t, report.Missing, 2) {
+		assert.Equal(t, "oci_test_parent.deleted", report.Missing[0].Address)
+		assert.Equal(t, "oci_test_child.deleted_child", report.Missing[1].Address)
+	}
+
+	if assert.Len(t, report.Changed, 1) {
+		assert.Equal(t, "oci_test_parent.changed", report.Changed[0].Address)
+		assert.Equal(t, []*driftAttribute{{Name: "display_name", State: "old_name", Live: "string0"}}, report.Changed[0].Attributes)
+	}
+
+	// Every other parent and child that was discovered isn't in the state
+	assert.Len(t, report.Unmanaged, 10)
+	for _, resource := range report.Unmanaged {
+		assert.NotEqual(t, getTestResourceId("parent", 0), resource.Id)
+		assert.NotEqual(t, getTestResourceId("child", 0), resource.Id)
+	}
+}
+
+func TestUnitRunDriftCommand_unsupportedStateVersion(t *testing.T) {
+	stateFile := fmt.Sprintf("discoveryTest-%d.tfstate", time.Now().Nanosecond())
+	if err := ioutil.WriteFile(stateFile, []byte(`{"version": 3, "modules": []}`), 0666); err != nil {
+		t.Fatalf("unable to write state file: %v", err)
+	}
+	defer os.Remove(stateFile)
+
+	if _, err := readTerraformState(stateFile); err == nil {
+		t.Errorf("expected an error for a v3 state file")
+	}
+}
+
+func TestUnitNormalizeDriftValue(t *testing.T) {
+	nestedSchema := &schema.Schema{
+		Type: schema.TypeList,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name":     {Type: schema.TypeString, Required: true},
+				"optional": {Type: schema.TypeString, Optional: true},
+				"computed": {Type: schema.TypeString, Computed: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		value    interface{}
+		tfSchema *schema.Schema
+		want     interface{}
+	}{
+		{"", &schema.Schema{Type: schema.TypeString}, nil},
+		{"value", &schema.Schema{Type: schema.TypeString}, "value"},
+		{[]interface{}{}, &schema.Schema{Type: schema.TypeList, Elem: schema.TypeString}, nil},
+		{[]interface{}{"b", "a"}, &schema.Schema{Type: schema.TypeList, Elem: schema.TypeString}, []interface{}{"b", "a"}},
+		{[]interface{}{"b", "a"}, &schema.Schema{Type: schema.TypeSet, Elem: schema.TypeString}, []interface{}{"a", "b"}},
+		{map[string]interface{}{"key": "", "other": "value"}, &schema.Schema{Type: schema.TypeMap}, map[string]interface{}{"other": "value"}},
+		{map[string]interface{}{"name": "a", "optional": "", "computed": "c"}, nestedSchema, []interface{}{map[string]interface{}{"name": "a"}}},
+		{[]interface{}{map[string]interface{}{"name": "a", "optional": nil}}, nestedSchema, []interface{}{map[string]interface{}{"name": "a"}}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, normalizeDriftValue(test.value, test.tfSchema), "normalizeDriftValue(%v)", test.value)
+	}
+}
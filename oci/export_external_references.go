@@ -0,0 +1,295 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	oci_core "github.com/oracle/oci-go-sdk/core"
+)
+
+const externalReferencesFile = "external_references.tf"
+
+// Matches the date and build suffix of platform image names, e.g. '-2020.05.26-0' in 'Oracle-Linux-7.8-2020.05.26-0'
+var platformImageBuildSuffixRegex = regexp.MustCompile(`-\d{4}\.\d{2}\.\d{2}-\d+$`)
+
+// Describes how to look up a resource that is referenced by the exported resources, but is not exported itself
+type externalReferenceHints struct {
+	dataSourceClass string
+	idArgument      string // Argument of the data source that is set to the OCID of the resource
+	idAttribute     string // Attribute of the data source with the OCID of the resource, if it isn't 'id'
+
+	// Custom function that returns the arguments of the data source, along with any variables that they use
+	getArgumentsFn func(clients *OracleClients, id string) (map[string]interface{}, map[string]string, error)
+}
+
+// Maps the resource types of OCIDs to the data sources that look them up. KMS keys are not looked up, since the
+// oci_kms_key data source needs the management endpoint of the key's vault, which can't be found from the key's OCID.
+var externalReferenceHintsMap = map[string]*externalReferenceHints{
+	"apigateway":           {dataSourceClass: "oci_apigateway_gateway", idArgument: "gateway_id"},
+	"compartment":          {dataSourceClass: "oci_identity_compartment", idArgument: "id"},
+	"fnfunc":               {dataSourceClass: "oci_functions_function", idArgument: "function_id"},
+	"image":                {dataSourceClass: "oci_core_images", idAttribute: "images.0.id", getArgumentsFn: getPlatformImageLookupArguments},
+	"networksecuritygroup": {dataSourceClass: "oci_core_network_security_group", idArgument: "network_security_group_id"},
+	"subnet":               {dataSourceClass: "oci_core_subnet", idArgument: "subnet_id"},
+	"vault":                {dataSourceClass: "oci_kms_vault", idArgument: "vault_id"},
+	"vcn":                  {dataSourceClass: "oci_core_vcn", idArgument: "vcn_id"},
+}
+
+// A variable used by the argument of a data source
+type externalReferenceVar string
+
+// A data source that looks up a resource outside of the export
+type externalDataSource struct {
+	TerraformResource
+	idAttribute string
+	arguments   map[string]interface{} // Strings, bools, variables, lists of strings and nested blocks as lists of maps
+	vars        map[string]string      // Variables used by the arguments, mapped to their default value
+}
+
+func newExternalDataSource(clients *OracleClients, id string, terraformName string) (*externalDataSource, error) {
+	hints, exists := externalReferenceHintsMap[getOcidResourceType(id)]
+	if !exists {
+		return nil, nil
+	}
+
+	dataSource := &externalDataSource{
+		TerraformResource: TerraformResource{
+			id:             id,
+			terraformClass: hints.dataSourceClass,
+			terraformName:  terraformName,
+		},
+		idAttribute: "id",
+		arguments:   map[string]interface{}{},
+		vars:        map[string]string{},
+	}
+	if hints.idAttribute != "" {
+		dataSource.idAttribute = hints.idAttribute
+	}
+
+	if hints.getArgumentsFn == nil {
+		dataSource.arguments[hints.idArgument] = id
+		return dataSource, nil
+	}
+
+	arguments, vars, err := hints.getArgumentsFn(clients, id)
+	if err != nil {
+		return nil, err
+	}
+	dataSource.arguments = arguments
+	dataSource.vars = vars
+	return dataSource, nil
+}
+
+// Returns the interpolation of the OCID that the data source looked up
+func (dataSource *externalDataSource) getIdInterpolation() string {
+	return tfHclVersion.getDataSourceHclString(dataSource.getTerraformReference(), dataSource.idAttribute)
+}
+
+// Replaces the OCIDs of resources that are referenced by the exported resources, but are not exported themselves, with
+// the data sources that look them up. Each resource is looked up once, and resources that can't be looked up keep
+// their OCIDs.
+func resolveExternalReferences(clients *OracleClients, exportCompartments []*exportCompartment) {
+	exportedIds := map[string]bool{}
+	for _, compartment := range exportCompartments {
+		for _, step := range compartment.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				exportedIds[resource.id] = true
+			}
+		}
+	}
+
+	// Data sources are named uniquely across compartments, since a root module may use the data sources of several compartments
+	dataSources := map[string]*externalDataSource{}
+	dataSourceCount := map[string]int{}
+	for _, compartment := range exportCompartments {
+		compartmentDataSources := map[*externalDataSource]bool{}
+		for _, step := range compartment.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				resourceSchema, exists := resourcesMap[resource.terraformClass]
+				if !exists {
+					continue
+				}
+
+				for _, value := range getConfigurableStringValues(resource.sourceAttributes, resourceSchema) {
+					// A variable that stands in for the OCID is replaced too, if the resource can be looked up
+					varName := getExternalReferenceVarName(resource, value)
+					if _, isResolved := compartment.referenceMap[value]; (isResolved && varName == "") || exportedIds[value] {
+						continue
+					}
+
+					dataSource, isLookedUp := dataSources[value]
+					if !isLookedUp {
+						ocidType := getOcidResourceType(value)
+						terraformName := fmt.Sprintf("external_%s_%d", ocidType, dataSourceCount[ocidType]+1)
+
+						var err error
+						if dataSource, err = newExternalDataSource(clients, value, terraformName); err != nil {
+							log.Printf("[WARN] unable to look up the external reference '%s' of '%s': %v", value, resource.getTerraformReference(), err)
+						}
+						if dataSource != nil {
+							dataSourceCount[ocidType]++
+						}
+						dataSources[value] = dataSource
+					}
+
+					if dataSource == nil {
+						continue
+					}
+
+					if !compartmentDataSources[dataSource] {
+						compartmentDataSources[dataSource] = true
+						compartment.externalDataSources = append(compartment.externalDataSources, dataSource)
+						for varName, defaultValue := range dataSource.vars {
+							compartment.vars[varName] = defaultValue
+						}
+					}
+					compartment.referenceMap[value] = dataSource.getIdInterpolation()
+					if varName != "" {
+						delete(compartment.vars, varName)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Returns the variable that the configuration of a resource uses instead of an OCID, or an empty string if there is
+// none. Instances use a variable for their source image, see processInstances.
+func getExternalReferenceVarName(resource *OCIResource, value string) string {
+	if imageId, ok := resource.sourceAttributes["image"].(string); ok && resource.terraformClass == "oci_core_instance" && imageId == value {
+		return getInstanceSourceImageVarName(resource)
+	}
+	return ""
+}
+
+// Looks up a platform image by its operating system and version, so that the latest build of the image is used in
+// any region or tenancy. Custom images can't be looked up, since they only exist in their own tenancy.
+func getPlatformImageLookupArguments(clients *OracleClients, id string) (map[string]interface{}, map[string]string, error) {
+	response, err := clients.computeClient().GetImage(context.Background(), oci_core.GetImageRequest{ImageId: &id})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if response.CompartmentId != nil {
+		return nil, nil, fmt.Errorf("[ERROR] image '%s' is a custom image", id)
+	}
+
+	if response.OperatingSystem == nil || response.OperatingSystemVersion == nil {
+		return nil, nil, fmt.Errorf("[ERROR] image '%s' has no operating system", id)
+	}
+
+	tenancyId := clients.configuration[tenancyOcidAttrName]
+
+	arguments := map[string]interface{}{
+		"compartment_id":           externalReferenceVar("tenancy_ocid"),
+		"operating_system":         *response.OperatingSystem,
+		"operating_system_version": *response.OperatingSystemVersion,
+		"state":                    string(oci_core.ImageLifecycleStateAvailable),
+		"sort_by":                  string(oci_core.ListImagesSortByTimecreated),
+		"sort_order":               string(oci_core.ListImagesSortOrderDesc),
+	}
+
+	if response.DisplayName != nil {
+		if displayNameRegex := getPlatformImageDisplayNameRegex(*response.DisplayName); displayNameRegex != "" {
+			arguments["filter"] = []map[string]interface{}{
+				{"name": "display_name", "values": []string{displayNameRegex}, "regex": true},
+			}
+		}
+	}
+
+	return arguments, map[string]string{"tenancy_ocid": fmt.Sprintf("\"%s\"", tenancyId)}, nil
+}
+
+// Returns a regex that matches any build of a platform image with the same name, e.g. to tell apart the GPU images of an
+// operating system version, or an empty string if the name isn't that of a platform image
+func getPlatformImageDisplayNameRegex(displayName string) string {
+	if !platformImageBuildSuffixRegex.MatchString(displayName) {
+		return ""
+	}
+	return fmt.Sprintf(`^%s-\d{4}\.\d{2}\.\d{2}-\d+$`, regexp.QuoteMeta(platformImageBuildSuffixRegex.ReplaceAllString(displayName, "")))
+}
+
+func (dataSource *externalDataSource) getHCLString(builder *strings.Builder) {
+	builder.WriteString(fmt.Sprintf("data %s %s {\n", dataSource.terraformClass, dataSource.terraformName))
+	builder.WriteString(getProviderMetaArgumentHclString())
+	writeExternalDataSourceArguments(builder, dataSource.arguments)
+	builder.WriteString("}\n\n")
+}
+
+func writeExternalDataSourceArguments(builder *strings.Builder, arguments map[string]interface{}) {
+	for _, name := range getSortedKeys(arguments) {
+		switch v := arguments[name].(type) {
+		case externalReferenceVar:
+			builder.WriteString(fmt.Sprintf("%s = %s\n", name, tfHclVersion.getVarHclString(string(v))))
+		case string:
+			builder.WriteString(fmt.Sprintf("%s = %q\n", name, v))
+		case []string:
+			values := make([]string, len(v))
+			for idx, value := range v {
+				values[idx] = fmt.Sprintf("%q", value)
+			}
+			builder.WriteString(fmt.Sprintf("%s = [%s]\n", name, strings.Join(values, ", ")))
+		case []map[string]interface{}:
+			for _, block := range v {
+				builder.WriteString(fmt.Sprintf("%s {\n", name))
+				writeExternalDataSourceArguments(builder, block)
+				builder.WriteString("}\n")
+			}
+		default:
+			builder.WriteString(fmt.Sprintf("%s = %v\n", name, v))
+		}
+	}
+}
+
+func (dataSource *externalDataSource) addToJsonConfig(config jsonConfig) {
+	body := getExternalDataSourceJsonBody(dataSource.arguments)
+	addProviderMetaArgument(body)
+	config.addBlock("data", body, dataSource.terraformClass, dataSource.terraformName)
+}
+
+func getExternalDataSourceJsonBody(arguments map[string]interface{}) map[string]interface{} {
+	body := map[string]interface{}{}
+	for name, argument := range arguments {
+		switch v := argument.(type) {
+		case externalReferenceVar:
+			body[name] = getJsonInterpolation(tfHclVersion.getVarHclString(string(v)))
+		case []map[string]interface{}:
+			blocks := make([]interface{}, len(v))
+			for idx, block := range v {
+				blocks[idx] = getExternalDataSourceJsonBody(block)
+			}
+			body[name] = blocks
+		default:
+			body[name] = v
+		}
+	}
+	return body
+}
+
+// Writes the data sources that look up the external references of a configuration
+func generateExternalReferencesFile(outputDir string, dataSources []*externalDataSource) error {
+	sortedDataSources := make([]*externalDataSource, len(dataSources))
+	copy(sortedDataSources, dataSources)
+	sort.Slice(sortedDataSources, func(i, j int) bool {
+		return sortedDataSources[i].getTerraformReference() < sortedDataSources[j].getTerraformReference()
+	})
+
+	if exportFormat == ExportFormatJson {
+		config := newJsonConfig()
+		for _, dataSource := range sortedDataSources {
+			dataSource.addToJsonConfig(config)
+		}
+		return writeExportJsonFile(outputDir, getExportConfigFileName(externalReferencesFile), config)
+	}
+
+	builder := &strings.Builder{}
+	builder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	for _, dataSource := range sortedDataSources {
+		dataSource.getHCLString(builder)
+	}
+	return writeExportConfigFile(outputDir, externalReferencesFile, builder.String())
+}
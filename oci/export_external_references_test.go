@@ -0,0 +1,185 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitExternalReferenceHints(t *testing.T) {
+	dataSources := DataSourcesMap()
+	for ocidType, hints := range externalReferenceHintsMap {
+		dataSource, exists := dataSources[hints.dataSourceClass]
+		if !exists {
+			t.Errorf("no data source '%s' for external references of type '%s'", hints.dataSourceClass, ocidType)
+			continue
+		}
+
+		if hints.getArgumentsFn == nil {
+			if _, exists := dataSource.Schema[hints.idArgument]; !exists {
+				t.Errorf("data source '%s' has no argument '%s'", hints.dataSourceClass, hints.idArgument)
+			}
+		}
+	}
+}
+
+func TestUnitResolveExternalReferences(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	imageHints := externalReferenceHintsMap["image"]
+	getImageArgumentsFn := imageHints.getArgumentsFn
+	defer func() { imageHints.getArgumentsFn = getImageArgumentsFn }()
+	imageHints.getArgumentsFn = func(clients *OracleClients, id string) (map[string]interface{}, map[string]string, error) {
+		if id != "ocid1.image.oc1.phx.platform" {
+			return nil, nil, fmt.Errorf("image '%s' is a custom image", id)
+		}
+		return map[string]interface{}{"compartment_id": externalReferenceVar("tenancy_ocid"), "operating_system": "Oracle Linux"}, map[string]string{"tenancy_ocid": "\"ocid1.tenancy.abc\""}, nil
+	}
+
+	newResource := func(id string, terraformName string, externalId string) *OCIResource {
+		return &OCIResource{
+			sourceAttributes:  map[string]interface{}{"compartment_id": "ocid1.compartment.abc", "a_string": externalId},
+			TerraformResource: TerraformResource{id: id, terraformClass: "oci_test_parent", terraformName: terraformName},
+		}
+	}
+	newCompartment := func(resources ...*OCIResource) *exportCompartment {
+		compartment := newExportCompartment("ocid1.compartment.abc", "", "export")
+		compartment.referenceMap = map[string]string{"ocid1.compartment.abc": "var.compartment_ocid"}
+		compartment.vars = map[string]string{}
+		compartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: resources}}
+		return compartment
+	}
+
+	compartment := newCompartment(
+		newResource("ocid1.parent.1", "export_parent_1", "ocid1.subnet.oc1.phx.external"),
+		newResource("ocid1.parent.2", "export_parent_2", "ocid1.image.oc1.phx.platform"),
+		newResource("ocid1.parent.3", "export_parent_3", "ocid1.image.oc1.phx.custom"),
+		newResource("ocid1.parent.4", "export_parent_4", "ocid1.key.oc1.phx.external"),
+	)
+	otherCompartment := newCompartment(
+		newResource("ocid1.parent.5", "export_parent_5", "ocid1.subnet.oc1.phx.external"),
+		newResource("ocid1.subnet.oc1.phx.exported", "export_subnet", "ocid1.parent.1"),
+		newResource("ocid1.parent.6", "export_parent_6", "ocid1.subnet.oc1.phx.exported"),
+	)
+	resolveExternalReferences(nil, []*exportCompartment{compartment, otherCompartment})
+
+	assert.Equal(t, "data.oci_core_subnet.external_subnet_1.id", compartment.referenceMap["ocid1.subnet.oc1.phx.external"])
+	assert.Equal(t, "data.oci_core_images.external_image_1.images.0.id", compartment.referenceMap["ocid1.image.oc1.phx.platform"])
+	assert.Equal(t, "\"ocid1.tenancy.abc\"", compartment.vars["tenancy_ocid"])
+	assert.Len(t, compartment.externalDataSources, 2)
+
+	// Resources that can't be looked up, and resources that are exported, keep their OCIDs
+	for _, id := range []string{"ocid1.image.oc1.phx.custom", "ocid1.key.oc1.phx.external"} {
+		_, isResolved := compartment.referenceMap[id]
+		assert.False(t, isResolved, "expected '%s' to not be looked up", id)
+	}
+	_, isResolved := otherCompartment.referenceMap["ocid1.subnet.oc1.phx.exported"]
+	assert.False(t, isResolved)
+
+	// Resources are looked up once across compartments
+	assert.Equal(t, []*externalDataSource{compartment.externalDataSources[0]}, otherCompartment.externalDataSources)
+	assert.Equal(t, "data.oci_core_subnet.external_subnet_1.id", otherCompartment.referenceMap["ocid1.subnet.oc1.phx.external"])
+}
+
+// Test that the source image variables of instances are replaced by the data sources of platform images
+func TestUnitResolveExternalReferences_instanceSourceImages(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	imageHints := externalReferenceHintsMap["image"]
+	getImageArgumentsFn := imageHints.getArgumentsFn
+	defer func() { imageHints.getArgumentsFn = getImageArgumentsFn }()
+	imageHints.getArgumentsFn = func(clients *OracleClients, id string) (map[string]interface{}, map[string]string, error) {
+		if id != "ocid1.image.oc1.phx.platform" {
+			return nil, nil, fmt.Errorf("image '%s' is a custom image", id)
+		}
+		return map[string]interface{}{"compartment_id": externalReferenceVar("tenancy_ocid"), "operating_system": "Oracle Linux"}, map[string]string{"tenancy_ocid": "\"ocid1.tenancy.abc\""}, nil
+	}
+
+	newInstance := func(terraformName string, imageId string) *OCIResource {
+		return &OCIResource{
+			sourceAttributes: map[string]interface{}{
+				"compartment_id": "ocid1.compartment.abc",
+				"image":          imageId,
+				"source_details": []interface{}{map[string]interface{}{"source_type": "image"}},
+			},
+			TerraformResource: TerraformResource{id: "ocid1.instance." + terraformName, terraformClass: "oci_core_instance", terraformName: terraformName},
+		}
+	}
+
+	referenceMap = map[string]string{"ocid1.compartment.abc": "var.compartment_ocid"}
+	vars = map[string]string{}
+	instances, err := processInstances(nil, []*OCIResource{
+		newInstance("export_platform_instance", "ocid1.image.oc1.phx.platform"),
+		newInstance("export_other_platform_instance", "ocid1.image.oc1.phx.platform"),
+		newInstance("export_custom_instance", "ocid1.image.oc1.phx.custom"),
+	})
+	if err != nil {
+		t.Fatalf("unable to process the instances: %v", err)
+	}
+
+	compartment := newExportCompartment("ocid1.compartment.abc", "", "export")
+	compartment.referenceMap = referenceMap
+	compartment.vars = vars
+	compartment.generateConfigSteps = []*GenerateConfigStep{{discoveredResources: instances}}
+	resolveExternalReferences(nil, []*exportCompartment{compartment})
+
+	assert.Equal(t, "data.oci_core_images.external_image_1.images.0.id", compartment.referenceMap["ocid1.image.oc1.phx.platform"])
+	assert.Len(t, compartment.externalDataSources, 1)
+	assert.NotContains(t, compartment.vars, "export_platform_instance_source_image_id")
+	assert.NotContains(t, compartment.vars, "export_other_platform_instance_source_image_id")
+
+	// Instances of custom images keep their variables
+	assert.Equal(t, "var.export_custom_instance_source_image_id", compartment.referenceMap["ocid1.image.oc1.phx.custom"])
+	assert.Equal(t, "\"ocid1.image.oc1.phx.custom\"", compartment.vars["export_custom_instance_source_image_id"])
+}
+
+func TestUnitGetPlatformImageDisplayNameRegex(t *testing.T) {
+	displayNameRegex := getPlatformImageDisplayNameRegex("Oracle-Linux-7.8-Gen2-GPU-2020.05.26-0")
+	assert.Equal(t, `^Oracle-Linux-7\.8-Gen2-GPU-\d{4}\.\d{2}\.\d{2}-\d+$`, displayNameRegex)
+	assert.Regexp(t, displayNameRegex, "Oracle-Linux-7.8-Gen2-GPU-2020.08.26-1")
+	assert.NotRegexp(t, displayNameRegex, "Oracle-Linux-7.8-2020.08.26-1")
+	assert.Equal(t, "", getPlatformImageDisplayNameRegex("my custom image"))
+}
+
+func TestUnitGenerateExternalReferencesFile(t *testing.T) {
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Fatalf("unable to mkdir %s. err: %v", outputDir, err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	dataSources := []*externalDataSource{
+		{
+			TerraformResource: TerraformResource{terraformClass: "oci_core_subnet", terraformName: "external_subnet_1"},
+			arguments:         map[string]interface{}{"subnet_id": "ocid1.subnet.oc1.phx.external"},
+		},
+		{
+			TerraformResource: TerraformResource{terraformClass: "oci_core_images", terraformName: "external_image_1"},
+			arguments: map[string]interface{}{
+				"compartment_id": externalReferenceVar("tenancy_ocid"),
+				"filter":         []map[string]interface{}{{"name": "display_name", "values": []string{`^Oracle-Linux-7\.8-\d+$`}, "regex": true}},
+			},
+		},
+	}
+
+	tfHclVersion = &TfHclVersion11{}
+	if err := generateExternalReferencesFile(outputDir, dataSources); err != nil {
+		t.Fatalf("unable to generate the external references: %v", err)
+	}
+
+	contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), externalReferencesFile))
+	if err != nil {
+		t.Fatalf("no %s file generated", externalReferencesFile)
+	}
+	assert.Regexp(t, `data oci_core_images external_image_1 \{\n\s+compartment_id = "\$\{var.tenancy_ocid\}"\n\s+filter \{\n\s+name\s+= "display_name"\n\s+regex\s+= true\n\s+values = \["\^Oracle-Linux-7\\\\.8-\\\\d\+\$"\]\n`, string(contents))
+	assert.Regexp(t, `data oci_core_subnet external_subnet_1 \{\n\s+subnet_id = "ocid1.subnet.oc1.phx.external"\n\}`, string(contents))
+}
@@ -0,0 +1,179 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	resourceFilterIncludePrefix = "include:"
+	resourceFilterExcludePrefix = "exclude:"
+	resourceFilterSeparator     = ";"
+)
+
+// Operators of filter conditions, ordered so that an operator is found before any operator that it contains
+var resourceFilterOperators = []string{"!=", "!~", "=", "~"}
+
+// A rule that includes or excludes the discovered resources that match all of its conditions.
+// e.g. 'exclude:type=oci_core_instance;state=TERMINATED'
+type resourceFilter struct {
+	exclude    bool
+	conditions []*resourceFilterCondition
+}
+
+// A condition on a single attribute of a resource, e.g. 'defined_tags.Team.Owner=payments' or 'display_name~^prod-'
+type resourceFilterCondition struct {
+	attribute string
+	operator  string
+	value     string
+	regex     *regexp.Regexp
+}
+
+type resourceFilters []*resourceFilter
+
+// Parses the filter expressions given to the export command
+func parseResourceFilters(expressions []string) (resourceFilters, error) {
+	result := resourceFilters{}
+	for _, expression := range expressions {
+		expression = strings.TrimSpace(expression)
+		if expression == "" {
+			continue
+		}
+
+		filter := &resourceFilter{exclude: strings.HasPrefix(expression, resourceFilterExcludePrefix)}
+		conditions := strings.TrimPrefix(strings.TrimPrefix(expression, resourceFilterExcludePrefix), resourceFilterIncludePrefix)
+
+		for _, conditionExpression := range strings.Split(conditions, resourceFilterSeparator) {
+			condition, err := parseResourceFilterCondition(strings.TrimSpace(conditionExpression))
+			if err != nil {
+				return nil, fmt.Errorf("[ERROR] invalid filter '%s': %v", expression, err)
+			}
+			filter.conditions = append(filter.conditions, condition)
+		}
+		result = append(result, filter)
+	}
+
+	return result, nil
+}
+
+func parseResourceFilterCondition(expression string) (*resourceFilterCondition, error) {
+	operatorIdx := -1
+	operator := ""
+	for _, candidate := range resourceFilterOperators {
+		if idx := strings.Index(expression, candidate); idx > 0 && (operatorIdx < 0 || idx < operatorIdx) {
+			operatorIdx, operator = idx, candidate
+		}
+	}
+	if operatorIdx < 0 {
+		return nil, fmt.Errorf("condition '%s' should be of the form <attribute><operator><value>, with one of the operators %s", expression, strings.Join(resourceFilterOperators, ", "))
+	}
+
+	condition := &resourceFilterCondition{
+		attribute: strings.TrimSpace(expression[:operatorIdx]),
+		operator:  operator,
+		value:     strings.TrimSpace(expression[operatorIdx+len(operator):]),
+	}
+
+	switch {
+	case condition.attribute == "type", condition.attribute == "state", condition.attribute == "display_name":
+	case strings.HasPrefix(condition.attribute, "freeform_tags.") && len(condition.attribute) > len("freeform_tags."):
+	case strings.HasPrefix(condition.attribute, "defined_tags.") && strings.Count(condition.attribute, ".") >= 2:
+	default:
+		return nil, fmt.Errorf("unsupported attribute '%s', the supported attributes are type, state, display_name, freeform_tags.<key> and defined_tags.<namespace>.<key>", condition.attribute)
+	}
+
+	if operator == "~" || operator == "!~" {
+		regex, err := regexp.Compile(condition.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression '%s': %v", condition.value, err)
+		}
+		condition.regex = regex
+	}
+
+	return condition, nil
+}
+
+// Returns whether a discovered resource should be exported. A resource is exported if it matches any include filter,
+// or if there are no include filters, and it doesn't match any exclude filter.
+func (filters resourceFilters) isExportable(resource *OCIResource) bool {
+	hasIncludeFilters := false
+	included := false
+	for _, filter := range filters {
+		matches := filter.matches(resource)
+		if filter.exclude {
+			if matches {
+				return false
+			}
+			continue
+		}
+
+		hasIncludeFilters = true
+		included = included || matches
+	}
+
+	return included || !hasIncludeFilters
+}
+
+func (filter *resourceFilter) matches(resource *OCIResource) bool {
+	for _, condition := range filter.conditions {
+		if !condition.matches(resource) {
+			return false
+		}
+	}
+	return true
+}
+
+func (condition *resourceFilterCondition) matches(resource *OCIResource) bool {
+	value, exists := getResourceFilterValue(resource, condition.attribute)
+
+	switch condition.operator {
+	case "=":
+		return exists && condition.equals(value)
+	case "!=":
+		return !exists || !condition.equals(value)
+	case "~":
+		return exists && condition.regex.MatchString(value)
+	case "!~":
+		return !exists || !condition.regex.MatchString(value)
+	}
+	return false
+}
+
+func (condition *resourceFilterCondition) equals(value string) bool {
+	// Lifecycle states are matched the same way as the discoverable lifecycle states of each resource
+	if condition.attribute == "state" {
+		return strings.EqualFold(value, condition.value)
+	}
+	return value == condition.value
+}
+
+// Returns the value of a filter attribute for a discovered resource, and whether the resource has a value for it
+func getResourceFilterValue(resource *OCIResource, attribute string) (string, bool) {
+	var value interface{}
+	switch {
+	case attribute == "type":
+		return resource.terraformClass, true
+	case attribute == "display_name":
+		// Resources without a display name are usually identified by their name instead
+		if value = resource.sourceAttributes["display_name"]; value == nil {
+			value = resource.sourceAttributes["name"]
+		}
+	case strings.HasPrefix(attribute, "freeform_tags."):
+		if tags, ok := resource.sourceAttributes["freeform_tags"].(map[string]interface{}); ok {
+			value = tags[strings.TrimPrefix(attribute, "freeform_tags.")]
+		}
+	case strings.HasPrefix(attribute, "defined_tags."):
+		// Defined tags are keyed by '<namespace>.<key>'
+		if tags, ok := resource.sourceAttributes["defined_tags"].(map[string]interface{}); ok {
+			value = tags[strings.TrimPrefix(attribute, "defined_tags.")]
+		}
+	default:
+		value = resource.sourceAttributes[attribute]
+	}
+
+	if stringValue, ok := value.(string); ok {
+		return stringValue, true
+	}
+	return "", false
+}
@@ -0,0 +1,127 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitParseResourceFilters(t *testing.T) {
+	filters, err := parseResourceFilters([]string{"defined_tags.Team.Owner=payments", " exclude: type=oci_core_instance ; state=TERMINATED ", ""})
+	if err != nil {
+		t.Fatalf("unexpected error parsing filters: %v", err)
+	}
+
+	if assert.Len(t, filters, 2) {
+		assert.False(t, filters[0].exclude)
+		assert.Equal(t, &resourceFilterCondition{attribute: "defined_tags.Team.Owner", operator: "=", value: "payments"}, filters[0].conditions[0])
+
+		assert.True(t, filters[1].exclude)
+		if assert.Len(t, filters[1].conditions, 2) {
+			assert.Equal(t, "type", filters[1].conditions[0].attribute)
+			assert.Equal(t, "TERMINATED", filters[1].conditions[1].value)
+		}
+	}
+
+	invalidFilters := []string{
+		"display_name",
+		"=value",
+		"unknown_attribute=value",
+		"defined_tags.Owner=payments",
+		"display_name~[",
+	}
+	for _, filter := range invalidFilters {
+		if _, err := parseResourceFilters([]string{filter}); err == nil {
+			t.Errorf("expected an error for filter '%s'", filter)
+		}
+	}
+}
+
+// Test the example of exporting everything tagged 'Team.Owner=payments' except terminated instances
+func TestUnitResourceFilters_isExportable(t *testing.T) {
+	filters, err := parseResourceFilters([]string{
+		"defined_tags.Team.Owner=payments",
+		"freeform_tags.project=checkout;display_name~^prod-",
+		"exclude:type=oci_core_instance;state=terminated",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error parsing filters: %v", err)
+	}
+
+	newResource := func(terraformClass string, sourceAttributes map[string]interface{}) *OCIResource {
+		return &OCIResource{
+			sourceAttributes:  sourceAttributes,
+			TerraformResource: TerraformResource{terraformClass: terraformClass},
+		}
+	}
+	paymentsTags := map[string]interface{}{"Team.Owner": "payments"}
+
+	tests := []struct {
+		resource *OCIResource
+		want     bool
+	}{
+		{newResource("oci_core_instance", map[string]interface{}{"defined_tags": paymentsTags, "state": "RUNNING"}), true},
+		{newResource("oci_core_instance", map[string]interface{}{"defined_tags": paymentsTags, "state": "TERMINATED"}), false},
+		{newResource("oci_core_volume", map[string]interface{}{"defined_tags": paymentsTags, "state": "TERMINATED"}), true},
+		{newResource("oci_core_vcn", map[string]interface{}{"defined_tags": map[string]interface{}{"Team.Owner": "search"}}), false},
+		{newResource("oci_core_vcn", map[string]interface{}{}), false},
+		{newResource("oci_objectstorage_bucket", map[string]interface{}{"name": "prod-bucket", "freeform_tags": map[string]interface{}{"project": "checkout"}}), true},
+		{newResource("oci_objectstorage_bucket", map[string]interface{}{"name": "dev-bucket", "freeform_tags": map[string]interface{}{"project": "checkout"}}), false},
+	}
+
+	for idx, test := range tests {
+		assert.Equal(t, test.want, filters.isExportable(test.resource), "test %d: isExportable(%v)", idx, test.resource.sourceAttributes)
+	}
+
+	// All resources are exported without any filters
+	assert.True(t, resourceFilters(nil).isExportable(newResource("oci_core_vcn", map[string]interface{}{})))
+}
+
+// Test that resources which are filtered out are still visited, so that their children can be exported
+func TestUnitFindResources_filters(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	exportParentDefinition.alwaysExportable = false
+	defer func() { exportParentDefinition.alwaysExportable = true }()
+
+	filters, err := parseResourceFilters([]string{"type=oci_test_child"})
+	if err != nil {
+		t.Fatalf("unexpected error parsing filters: %v", err)
+	}
+
+	discoveryPool := newResourceDiscoveryPool(&OracleClients{}, 1)
+	discoveryPool.filters = filters
+	results, err := discoveryPool.findResources(getRootCompartmentResource(), compartmentTestingResourceGraph, nil)
+	if err != nil {
+		t.Fatalf("unexpected error discovering resources: %v", err)
+	}
+
+	exportedChildren := 0
+	for _, resource := range results {
+		if resource.terraformClass == "oci_test_parent" {
+			assert.True(t, resource.omitFromExport, "expected parent '%s' to be omitted", resource.id)
+		} else if !resource.omitFromExport {
+			exportedChildren++
+		}
+	}
+	assert.Equal(t, len(childrenResources), exportedChildren)
+}
+
+// Test that omitted resources are exported if an exported resource refers to them by interpolation
+func TestUnitIncludeInterpolatedResources(t *testing.T) {
+	backendSet := &OCIResource{
+		TerraformResource: TerraformResource{terraformClass: "oci_load_balancer_backend_set", terraformName: "export_backend_set", omitFromExport: true},
+	}
+	otherBackendSet := &OCIResource{
+		TerraformResource: TerraformResource{terraformClass: "oci_load_balancer_backend_set", terraformName: "export_other_backend_set", omitFromExport: true},
+	}
+	listener := &OCIResource{
+		sourceAttributes:  map[string]interface{}{"default_backend_set_name": InterpolationString{"oci_load_balancer_backend_set.export_backend_set.name"}},
+		TerraformResource: TerraformResource{terraformClass: "oci_load_balancer_listener", terraformName: "export_listener"},
+	}
+
+	includeInterpolatedResources([]*OCIResource{backendSet, otherBackendSet, listener})
+	assert.False(t, backendSet.omitFromExport)
+	assert.True(t, otherBackendSet.omitFromExport)
+}
@@ -21,19 +21,46 @@ var availabilityDomainsGraph = TerraformResourceGraph{
 	},
 }
 
+// Used to find the sub-compartments of the exported compartment when exporting recursively
+var compartmentTreeResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{
+			TerraformResourceHints: exportIdentityCompartmentHints,
+			datasourceQueryParams:  map[string]string{"compartment_id": "id"},
+		},
+	},
+}
+
 var compartmentResourceGraphs = map[string]TerraformResourceGraph{
+	"apigateway":          apigatewayResourceGraph,
 	"availability_domain": availabilityDomainsGraph,
 	"auto_scaling":        autoScalingResourceGraph,
 	"bds":                 bdsResourceGraph,
 	"containerengine":     containerengineResourceGraph,
 	"core":                coreResourceGraph,
 	"database":            databaseResourceGraph,
+	"dns":                 dnsResourceGraph,
+	"events":              eventsResourceGraph,
+	"file_storage":        fileStorageResourceGraph,
 	"functions":           functionsResourceGraph,
+	"kms":                 kmsResourceGraph,
 	"load_balancer":       loadBalancerResourceGraph,
+	"monitoring":          monitoringResourceGraph,
 	"object_storage":      objectStorageResourceGraph,
+	"ons":                 onsResourceGraph,
+	"streaming":           streamingResourceGraph,
 	"tagging":             taggingResourceGraph,
 }
 
+// Deployments are discovered in the compartment rather than under their gateway, since they may be in a different
+// compartment than the gateway
+var apigatewayResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportApigatewayGatewayHints},
+		{TerraformResourceHints: exportApigatewayDeploymentHints},
+	},
+}
+
 var autoScalingResourceGraph = TerraformResourceGraph{
 	"oci_identity_compartment": {
 		{TerraformResourceHints: exportAutoScalingAutoScalingConfigurationHints},
@@ -170,6 +197,73 @@ var databaseResourceGraph = TerraformResourceGraph{
 	},
 }
 
+var dnsResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportDnsSteeringPolicyAttachmentHints},
+		{TerraformResourceHints: exportDnsSteeringPolicyHints},
+		{TerraformResourceHints: exportDnsTsigKeyHints},
+		{TerraformResourceHints: exportDnsZoneHints},
+	},
+	"oci_dns_zone": {
+		{
+			TerraformResourceHints: exportDnsRecordHints,
+			datasourceQueryParams: map[string]string{
+				"zone_name_or_id": "id",
+			},
+		},
+	},
+}
+
+var eventsResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportEventsRuleHints},
+	},
+}
+
+// Resources in this graph are scoped to an availability domain, so they are discovered under each of the
+// availability domains found by the availability_domain service
+var fileStorageResourceGraph = TerraformResourceGraph{
+	"oci_identity_availability_domain": {
+		{
+			TerraformResourceHints: exportFileStorageFileSystemHints,
+			datasourceQueryParams: map[string]string{
+				"availability_domain": "name",
+			},
+		},
+		{
+			TerraformResourceHints: exportFileStorageMountTargetHints,
+			datasourceQueryParams: map[string]string{
+				"availability_domain": "name",
+			},
+		},
+	},
+	"oci_file_storage_file_system": {
+		{
+			TerraformResourceHints: exportFileStorageSnapshotHints,
+			datasourceQueryParams: map[string]string{
+				"file_system_id": "id",
+			},
+		},
+	},
+	"oci_file_storage_mount_target": {
+		{
+			TerraformResourceHints: exportFileStorageExportSetHints,
+			datasourceQueryParams: map[string]string{
+				"availability_domain": "availability_domain",
+				"id":                  "export_set_id",
+			},
+		},
+	},
+	"oci_file_storage_export_set": {
+		{
+			TerraformResourceHints: exportFileStorageExportHints,
+			datasourceQueryParams: map[string]string{
+				"export_set_id": "id",
+			},
+		},
+	},
+}
+
 var functionsResourceGraph = TerraformResourceGraph{
 	"oci_identity_compartment": {
 		{TerraformResourceHints: exportFunctionsApplicationHints},
@@ -261,6 +355,29 @@ var identityResourceGraph = TerraformResourceGraph{
 	},
 }
 
+var kmsResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportKmsVaultHints},
+	},
+	"oci_kms_vault": {
+		{
+			TerraformResourceHints: exportKmsKeyHints,
+			datasourceQueryParams: map[string]string{
+				"management_endpoint": "management_endpoint",
+			},
+		},
+	},
+	"oci_kms_key": {
+		{
+			TerraformResourceHints: exportKmsKeyVersionHints,
+			datasourceQueryParams: map[string]string{
+				"key_id":              "id",
+				"management_endpoint": "management_endpoint",
+			},
+		},
+	},
+}
+
 var limitsResourceGraph = TerraformResourceGraph{
 	"oci_identity_tenancy": {
 		{TerraformResourceHints: exportLimitsQuotaHints},
@@ -315,6 +432,12 @@ var loadBalancerResourceGraph = TerraformResourceGraph{
 	},
 }
 
+var monitoringResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportMonitoringAlarmHints},
+	},
+}
+
 var objectStorageResourceGraph = TerraformResourceGraph{
 	"oci_identity_compartment": {
 		{TerraformResourceHints: exportObjectStorageNamespaceHints},
@@ -329,6 +452,22 @@ var objectStorageResourceGraph = TerraformResourceGraph{
 	},
 }
 
+var onsResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportOnsNotificationTopicHints},
+		{TerraformResourceHints: exportOnsSubscriptionHints},
+	},
+}
+
+// Streams are discovered in the compartment rather than under their stream pool, since they may be in a different
+// compartment than the stream pool
+var streamingResourceGraph = TerraformResourceGraph{
+	"oci_identity_compartment": {
+		{TerraformResourceHints: exportStreamingStreamPoolHints},
+		{TerraformResourceHints: exportStreamingStreamHints},
+	},
+}
+
 var taggingResourceGraph = TerraformResourceGraph{
 	"oci_identity_compartment": {
 		{TerraformResourceHints: exportIdentityTagDefaultHints},
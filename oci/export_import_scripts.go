@@ -0,0 +1,157 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	importShellScriptFile      = "import.sh"
+	importPowerShellScriptFile = "import.ps1"
+)
+
+var importScriptGroupNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// The import commands of the resources generated in a single config file
+type importScriptGroup struct {
+	name       string // Name of the function that imports the group, e.g. 'core' for 'core.tf'
+	configFile string // Path of the config file relative to the output directory
+	resources  []*importScriptResource
+}
+
+type importScriptResource struct {
+	address  string
+	importId string
+}
+
+// Writes shell and PowerShell scripts that import the generated resources with 'terraform import', so that they can be
+// imported into any backend. The commands are grouped by the config file of the resources, and each group can be run
+// on its own by passing its name to the script.
+func generateImportScripts(outputDir string, generateConfigSteps []*GenerateConfigStep, resourceModules map[*OCIResource]string) ([]string, error) {
+	groups := []*importScriptGroup{}
+	resourceCount := 0
+	for _, step := range generateConfigSteps {
+		configFile := step.configOutputFile
+		if relativeConfigFile, err := filepath.Rel(outputDir, step.configOutputFile); err == nil {
+			configFile = relativeConfigFile
+		}
+
+		group := &importScriptGroup{
+			name:       importScriptGroupNameRegex.ReplaceAllString(strings.TrimSuffix(strings.TrimSuffix(filepath.ToSlash(configFile), ".json"), ".tf"), "_"),
+			configFile: filepath.ToSlash(configFile),
+		}
+
+		// Resources are discovered in the order of the resource graph, so parents are imported before their children
+		for _, resource := range step.discoveredResources {
+			resourceSchema, exists := resourcesMap[resource.terraformClass]
+			if !exists || resourceSchema.Importer == nil {
+				log.Printf("[INFO] skip adding '%s' to the import scripts since it does not support import", resource.getTerraformReference())
+				continue
+			}
+
+			address := resource.getTerraformReference()
+			if moduleName, isModuleResource := resourceModules[resource]; isModuleResource {
+				address = fmt.Sprintf("module.%s.%s", moduleName, address)
+			}
+
+			importId := resource.importId
+			if len(importId) == 0 {
+				importId = resource.id
+			}
+
+			group.resources = append(group.resources, &importScriptResource{address: address, importId: importId})
+		}
+
+		if len(group.resources) > 0 {
+			groups = append(groups, group)
+			resourceCount += len(group.resources)
+		}
+	}
+
+	if err := writeImportScript(outputDir, importShellScriptFile, getImportShellScript(groups), 0777); err != nil {
+		return nil, err
+	}
+
+	if err := writeImportScript(outputDir, importPowerShellScriptFile, getImportPowerShellScript(groups), 0666); err != nil {
+		return nil, err
+	}
+
+	return []string{
+		fmt.Sprintf("Generated import scripts '%s%s%s' and '%s%s%s' for %d resources", outputDir, string(os.PathSeparator), importShellScriptFile, outputDir, string(os.PathSeparator), importPowerShellScriptFile, resourceCount),
+	}, nil
+}
+
+func getImportShellScript(groups []*importScriptGroup) string {
+	builder := &strings.Builder{}
+	builder.WriteString("#!/bin/sh\n")
+	builder.WriteString("## This script was generated by terraform-provider-oci\n")
+	builder.WriteString("## Run it from this directory after 'terraform init' to import the generated resources.\n")
+	builder.WriteString("## To only import the resources of some config files, pass the names of their groups, e.g. './import.sh core'\n")
+	builder.WriteString("set -e\n")
+
+	groupNames := []string{}
+	for _, group := range groups {
+		builder.WriteString(fmt.Sprintf("\n# %s\nimport_%s() {\n", group.configFile, group.name))
+		for _, resource := range group.resources {
+			builder.WriteString(fmt.Sprintf("  terraform import %s %s\n", quoteShellArgument(resource.address), quoteShellArgument(resource.importId)))
+		}
+		builder.WriteString("}\n")
+		groupNames = append(groupNames, group.name)
+	}
+
+	builder.WriteString(fmt.Sprintf("\nif [ $# -eq 0 ]; then\n  set -- %s\nfi\n\n", strings.Join(groupNames, " ")))
+	builder.WriteString("for group in \"$@\"; do\n  \"import_$group\"\ndone\n")
+	return builder.String()
+}
+
+func getImportPowerShellScript(groups []*importScriptGroup) string {
+	builder := &strings.Builder{}
+	builder.WriteString("## This script was generated by terraform-provider-oci\n")
+	builder.WriteString("## Run it from this directory after 'terraform init' to import the generated resources.\n")
+	builder.WriteString("## To only import the resources of some config files, pass the names of their groups, e.g. './import.ps1 core'\n")
+
+	groupNames := []string{}
+	for _, group := range groups {
+		groupNames = append(groupNames, quotePowerShellArgument(group.name))
+	}
+	builder.WriteString(fmt.Sprintf("param([string[]]$Groups = @(%s))\n", strings.Join(groupNames, ", ")))
+	builder.WriteString("$ErrorActionPreference = \"Stop\"\n\n")
+	builder.WriteString("function Import-Resource([string]$Address, [string]$Id) {\n")
+	builder.WriteString("  terraform import $Address $Id\n")
+	builder.WriteString("  if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }\n")
+	builder.WriteString("}\n")
+
+	for _, group := range groups {
+		builder.WriteString(fmt.Sprintf("\n# %s\nfunction Import-%s {\n", group.configFile, group.name))
+		for _, resource := range group.resources {
+			builder.WriteString(fmt.Sprintf("  Import-Resource %s %s\n", quotePowerShellArgument(resource.address), quotePowerShellArgument(resource.importId)))
+		}
+		builder.WriteString("}\n")
+	}
+
+	builder.WriteString("\nforeach ($group in $Groups) {\n  & \"Import-$group\"\n}\n")
+	return builder.String()
+}
+
+func quoteShellArgument(value string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(value, "'", `'\''`, -1))
+}
+
+func quotePowerShellArgument(value string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(value, "'", "''", -1))
+}
+
+// Writes an import script to the output directory, replacing any existing script
+func writeImportScript(outputDir string, fileName string, contents string, perm os.FileMode) error {
+	tmpOutputFile := fmt.Sprintf("%s%s%s.tmp", outputDir, string(os.PathSeparator), fileName)
+	if err := ioutil.WriteFile(tmpOutputFile, []byte(contents), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpOutputFile, fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName))
+}
@@ -0,0 +1,70 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the import scripts import every generated resource, with parents imported before their children
+func TestUnitRunExportCommand_generateImports(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	readFile := func(fileName string) string {
+		contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName))
+		if err != nil {
+			t.Fatalf("no %s file generated", fileName)
+		}
+		return string(contents)
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId:   &compartmentId,
+		Services:        []string{"compartment_testing"},
+		OutputDir:       &outputDir,
+		GenerateImports: true,
+		TFVersion:       &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	shellScript := readFile(importShellScriptFile)
+	assert.Contains(t, shellScript, "# compartment_testing.tf\nimport_compartment_testing() {\n")
+	assert.Contains(t, shellScript, "set -- compartment_testing\n")
+	assert.Equal(t, len(parentResources)+len(childrenResources), strings.Count(shellScript, "terraform import '"))
+
+	powerShellScript := readFile(importPowerShellScriptFile)
+	assert.Contains(t, powerShellScript, "param([string[]]$Groups = @('compartment_testing'))")
+	assert.Equal(t, len(parentResources)+len(childrenResources), strings.Count(powerShellScript, "Import-Resource '"))
+
+	for i := 0; i < len(parentResources); i++ {
+		parentLine := fmt.Sprintf("terraform import 'oci_test_parent.export_string%d' '%s'", i, getTestResourceId("parent", i))
+		parentIdx := strings.Index(shellScript, parentLine)
+		assert.True(t, parentIdx >= 0, "expected '%s' in the shell script", parentLine)
+
+		childIdx := strings.Index(shellScript, fmt.Sprintf("'oci_test_child.export_string%d_child_1'", i))
+		assert.True(t, childIdx > parentIdx, "expected the children of parent %d to be imported after it", i)
+	}
+}
+
+func TestUnitQuoteImportScriptArguments(t *testing.T) {
+	assert.Equal(t, `'namespace/bucket'`, quoteShellArgument("namespace/bucket"))
+	assert.Equal(t, `'it'\''s'`, quoteShellArgument("it's"))
+	assert.Equal(t, `'it''s'`, quotePowerShellArgument("it's"))
+}
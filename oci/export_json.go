@@ -0,0 +1,271 @@
+package oci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+type ExportFormatEnum string
+
+// Set of constants representing the allowed values for ExportFormatEnum
+const (
+	ExportFormatHcl  ExportFormatEnum = "hcl"
+	ExportFormatJson ExportFormatEnum = "json"
+)
+
+const (
+	jsonConfigFileSuffix = ".json"
+	jsonCommentKey       = "//"
+)
+
+var exportFormat = ExportFormatHcl
+
+// A configuration in the Terraform JSON syntax. Maps each block type (e.g. 'resource') to the labels and bodies of the
+// blocks of that type.
+type jsonConfig map[string]interface{}
+
+func newJsonConfig() jsonConfig {
+	return jsonConfig{jsonCommentKey: "This configuration was generated by terraform-provider-oci"}
+}
+
+// Adds a block to the configuration, nesting its body under each of its labels
+// e.g. a resource is added with the labels '<resource class>' and '<resource name>'
+func (config jsonConfig) addBlock(blockType string, body map[string]interface{}, labels ...string) {
+	parent := map[string]interface{}(config)
+	key := blockType
+	for _, label := range labels {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			parent[key] = child
+		}
+		parent, key = child, label
+	}
+	parent[key] = body
+}
+
+// Returns the name of a config file for the export format, e.g. 'vars.tf.json' for 'vars.tf'
+func getExportConfigFileName(fileName string) string {
+	if exportFormat == ExportFormatJson {
+		return fileName + jsonConfigFileSuffix
+	}
+	return fileName
+}
+
+// Converts an interpolation of any TfHclVersion to the template string syntax used by JSON configurations
+func getJsonInterpolation(interpolation string) string {
+	return fmt.Sprintf("${%s}", getInterpolationExpression(interpolation))
+}
+
+// Converts a literal value as written in HCL configurations (e.g. the default of a variable) to a JSON value
+func getJsonLiteral(hclLiteral string) interface{} {
+	if value, err := strconv.Unquote(hclLiteral); err == nil {
+		return value
+	}
+	return hclLiteral
+}
+
+func (ociRes *OCIResource) addToJsonConfig(config jsonConfig, interpolationMap map[string]string) error {
+	resourceInterpolationMap := ociRes.getResourceInterpolationMap(interpolationMap)
+
+	if ociRes.addJsonConfigFn != nil {
+		return ociRes.addJsonConfigFn(config, ociRes, resourceInterpolationMap)
+	}
+	return addJsonConfigFromGenericMap(config, ociRes, resourceInterpolationMap)
+}
+
+func addJsonConfigFromGenericMap(config jsonConfig, ociRes *OCIResource, interpolationMap map[string]string) error {
+	body, err := getJsonBodyFromMap(ociRes.sourceAttributes, resourcesMap[ociRes.terraformClass], interpolationMap)
+	if err != nil {
+		return err
+	}
+
+	addProviderMetaArgument(body)
+	config.addBlock("resource", body, ociRes.terraformClass, ociRes.terraformName)
+	return nil
+}
+
+// Builds the JSON body of a block from the source attributes, following the same rules as getHCLStringFromMap.
+// Attributes that could not be discovered are listed in the body's comment property.
+func getJsonBodyFromMap(sourceAttributes map[string]interface{}, resourceSchema *schema.Resource, interpolationMap map[string]string) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	comments := []string{}
+
+	for tfAttribute, tfSchema := range resourceSchema.Schema {
+		if tfSchema.Deprecated != "" || tfSchema.Removed != "" || (!tfSchema.Required && !tfSchema.Optional) {
+			continue
+		}
+
+		if attributeVal, exists := sourceAttributes[tfAttribute]; exists && attributeVal != nil {
+			value, err := getJsonValue(tfAttribute, attributeVal, tfSchema, interpolationMap)
+			if err != nil {
+				return nil, err
+			}
+
+			if value != nil {
+				body[tfAttribute] = value
+				continue
+			}
+		} else if exists {
+			log.Printf("[INFO] TF attribute '%s' is nil in source\n", tfAttribute)
+			if !tfSchema.Required {
+				continue
+			}
+		}
+
+		if tfSchema.Required {
+			log.Printf("[WARN] Required TF attribute '%s' not found in source\n", tfAttribute)
+			comments = append(comments, fmt.Sprintf("%s = <<Required attribute not found in discovery>>", tfAttribute))
+			isMissingRequiredAttributes = true
+		} else if tfSchema.Optional {
+			log.Printf("[INFO] Optional TF attribute '%s' not found in source\n", tfAttribute)
+			comments = append(comments, fmt.Sprintf("%s = <<Optional value not found in discovery>>", tfAttribute))
+		}
+	}
+
+	if len(comments) > 0 {
+		sort.Strings(comments)
+		body[jsonCommentKey] = comments
+	}
+
+	return body, nil
+}
+
+// Converts a source attribute to its JSON representation. Returns nil if the value has an unknown type.
+func getJsonValue(tfAttribute string, attributeVal interface{}, tfSchema *schema.Schema, interpolationMap map[string]string) (interface{}, error) {
+	switch v := attributeVal.(type) {
+	case InterpolationString:
+		return getJsonInterpolation(v.value), nil
+	case string:
+		if varOverride, exists := interpolationMap[v]; exists {
+			return getJsonInterpolation(varOverride), nil
+		}
+		return v, nil
+	case int, bool, float64:
+		return v, nil
+	case []interface{}:
+		switch tfSchema.Type {
+		case schema.TypeList, schema.TypeSet:
+			switch elem := tfSchema.Elem.(type) {
+			case *schema.Resource:
+				// Nested blocks are represented as a list of objects
+				blocks := []interface{}{}
+				for _, item := range v {
+					if val, ok := item.(map[string]interface{}); ok && val != nil {
+						block, err := getJsonBodyFromMap(val, elem, interpolationMap)
+						if err != nil {
+							return nil, err
+						}
+						blocks = append(blocks, block)
+					}
+				}
+				return blocks, nil
+			case *schema.Schema, schema.ValueType, InterpolationString:
+				items := []interface{}{}
+				for _, item := range v {
+					switch trueListVal := item.(type) {
+					case InterpolationString, string, int, bool, float64:
+						listVal, err := getJsonValue(tfAttribute, trueListVal, tfSchema, interpolationMap)
+						if err != nil {
+							return nil, err
+						}
+						items = append(items, listVal)
+					default:
+						return nil, fmt.Errorf("[ERROR] sourceAttribute '%s', tfAttribute '%s': List element type mismatch", tfAttribute, tfAttribute)
+					}
+				}
+				return items, nil
+			}
+
+			return nil, fmt.Errorf("[ERROR] sourceAttribute '%s', tfAttribute '%s': List element is neither schema.Resource or schema.Schema", tfAttribute, tfAttribute)
+		}
+	case map[string]interface{}:
+		switch tfSchema.Type {
+		case schema.TypeList:
+			if nestedResource, ok := tfSchema.Elem.(*schema.Resource); ok && nestedResource != nil {
+				block, err := getJsonBodyFromMap(v, nestedResource, interpolationMap)
+				if err != nil {
+					return nil, err
+				}
+				return []interface{}{block}, nil
+			}
+			return nil, fmt.Errorf("[ERROR] sourceAttribute '%s', tfAttribute '%s': Nested resource type mismatch", tfAttribute, tfAttribute)
+		case schema.TypeMap:
+			result := map[string]interface{}{}
+			for mapKey, mapVal := range v {
+				switch mapVal.(type) {
+				case InterpolationString, string, int, bool, float64:
+					jsonVal, err := getJsonValue(tfAttribute, mapVal, tfSchema, interpolationMap)
+					if err != nil {
+						return nil, err
+					}
+					result[mapKey] = jsonVal
+				default:
+					log.Printf("[WARN] TF attribute '%s' has a complex value for map key '%s'\n", tfAttribute, mapKey)
+				}
+			}
+			return result, nil
+		default:
+			return nil, fmt.Errorf("[ERROR] sourceAttribute '%s', tfAttribute '%s': Source attribute is nested object but TF attribute is not", tfAttribute, tfAttribute)
+		}
+	}
+
+	log.Printf("[WARN] TF attribute '%s' is unknown type in source\n", tfAttribute)
+	return nil, nil
+}
+
+// Writes a JSON config file for the step and returns its path
+func generateJsonConfigFile(outputDir string, step *GenerateConfigStep, interpolationMap map[string]string) (string, error) {
+	// Note that we still build a config file even if no resources were discovered, for the same reason as HCL configs
+	config := newJsonConfig()
+	for _, resource := range step.discoveredResources {
+		log.Printf("[INFO] ===> Generating resource '%s'", resource.getTerraformReference())
+		if err := resource.addToJsonConfig(config, interpolationMap); err != nil {
+			return "", err
+		}
+	}
+
+	fileName := getExportConfigFileName(fmt.Sprintf("%s.tf", step.stepName))
+	if err := writeExportJsonFile(outputDir, fileName, config); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName), nil
+}
+
+// Writes a JSON config to the output directory, replacing any existing file
+func writeExportJsonFile(outputDir string, fileName string, config jsonConfig) error {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	// Interpolations may contain characters such as '<' that should be written as-is
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+
+	tmpOutputFile := fmt.Sprintf("%s%s%s.tmp", outputDir, string(os.PathSeparator), fileName)
+	outputFile := fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName)
+	file, err := os.OpenFile(tmpOutputFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(buffer.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpOutputFile, outputFile)
+}
@@ -0,0 +1,131 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the export command writes JSON configuration files that can be parsed
+func TestUnitRunExportCommand_json(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+	defer func() { exportFormat = ExportFormatHcl }()
+
+	tfHclVersions := []TfHclVersion{&TfHclVersion11{}, &TfHclVersion12{}}
+	for _, tfVersion := range tfHclVersions {
+		tfHclVersion = tfVersion
+		args := &ExportCommandArgs{
+			CompartmentId: &compartmentId,
+			Services:      []string{"compartment_testing"},
+			OutputDir:     &outputDir,
+			GenerateState: false,
+			TFVersion:     &tfHclVersion,
+			Format:        ExportFormatJson,
+		}
+
+		if err = RunExportCommand(args); err != nil {
+			t.Logf("(TF version %s) export command failed due to err: %v", tfHclVersion.toString(), err)
+			t.Fail()
+		}
+
+		for _, file := range []string{"compartment_testing.tf.json", "provider.tf.json", "vars.tf.json"} {
+			contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), file))
+			if err != nil {
+				t.Logf("(TF version %s) no %s file generated", tfHclVersion.toString(), file)
+				t.Fail()
+				continue
+			}
+
+			config := map[string]interface{}{}
+			if err := json.Unmarshal(contents, &config); err != nil {
+				t.Logf("(TF version %s) %s is not valid JSON: %v", tfHclVersion.toString(), file, err)
+				t.Fail()
+			}
+		}
+
+		contents, _ := ioutil.ReadFile(fmt.Sprintf("%s%scompartment_testing.tf.json", outputDir, string(os.PathSeparator)))
+		config := struct {
+			Resource map[string]map[string]map[string]interface{} `json:"resource"`
+		}{}
+		if err := json.Unmarshal(contents, &config); err != nil {
+			t.Logf("(TF version %s) unable to read the resources: %v", tfHclVersion.toString(), err)
+			t.Fail()
+		}
+
+		assert.Equal(t, "${oci_test_parent.export_string0.id}", config.Resource["oci_test_child"]["export_string0_child_1"]["parent_id"])
+		assert.Equal(t, "string0", config.Resource["oci_test_parent"]["export_string0"]["compartment_id"])
+
+		providerContents, _ := ioutil.ReadFile(fmt.Sprintf("%s%sprovider.tf.json", outputDir, string(os.PathSeparator)))
+		providerConfig := struct {
+			Provider map[string]map[string]interface{} `json:"provider"`
+		}{}
+		if err := json.Unmarshal(providerContents, &providerConfig); err != nil {
+			t.Logf("(TF version %s) unable to read the provider: %v", tfHclVersion.toString(), err)
+			t.Fail()
+		}
+		assert.Equal(t, "${var.region}", providerConfig.Provider["oci"]["region"])
+	}
+}
+
+func TestUnitGetJsonBodyFromMap(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	sourceAttributes := map[string]interface{}{
+		"compartment_id": "ocid1.compartment.abc",
+		"a_bool":         true,
+		"a_int":          1,
+		"a_list":         []interface{}{"ocid1.compartment.abc", "listVal"},
+		"a_map":          map[string]interface{}{"key": "ocid1.compartment.abc"},
+		"a_nested":       []interface{}{map[string]interface{}{"nested_string": "nestedVal", "nested_int": 2}},
+		"a_string":       InterpolationString{"oci_test_parent.export_parent.name"},
+	}
+	interpolationMap := map[string]string{"ocid1.compartment.abc": "var.compartment_ocid"}
+
+	body, err := getJsonBodyFromMap(sourceAttributes, resourcesMap["oci_test_parent"], interpolationMap)
+	if err != nil {
+		t.Fatalf("got error from getJsonBodyFromMap: %v", err)
+	}
+
+	assert.Equal(t, "${var.compartment_ocid}", body["compartment_id"])
+	assert.Equal(t, true, body["a_bool"])
+	assert.Equal(t, 1, body["a_int"])
+	assert.Equal(t, []interface{}{"${var.compartment_ocid}", "listVal"}, body["a_list"])
+	assert.Equal(t, map[string]interface{}{"key": "${var.compartment_ocid}"}, body["a_map"])
+	assert.Equal(t, "${oci_test_parent.export_parent.name}", body["a_string"])
+
+	nestedBlocks, ok := body["a_nested"].([]interface{})
+	if !ok || len(nestedBlocks) != 1 {
+		t.Fatalf("expected a single nested block but got %v", body["a_nested"])
+	}
+	nestedBlock := nestedBlocks[0].(map[string]interface{})
+	assert.Equal(t, "nestedVal", nestedBlock["nested_string"])
+	assert.Equal(t, 2, nestedBlock["nested_int"])
+
+	// Attributes that weren't discovered are listed in the comment property
+	if _, hasComments := body[jsonCommentKey]; !hasComments {
+		t.Logf("expected a comment for the attributes that weren't discovered")
+		t.Fail()
+	}
+}
+
+func TestUnitGetJsonInterpolation(t *testing.T) {
+	assert.Equal(t, "${var.compartment_ocid}", getJsonInterpolation((&TfHclVersion11{}).getVarHclString("compartment_ocid")))
+	assert.Equal(t, "${var.compartment_ocid}", getJsonInterpolation((&TfHclVersion12{}).getVarHclString("compartment_ocid")))
+	assert.Equal(t, "${data.oci_identity_availability_domain.export_AD-1.name}", getJsonInterpolation((&TfHclVersion12{}).getDataSourceHclString("oci_identity_availability_domain.export_AD-1", "name")))
+}
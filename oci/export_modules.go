@@ -0,0 +1,376 @@
+package oci
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type ModuleLayoutEnum string
+
+// Set of constants representing the allowed values for ModuleLayoutEnum
+const (
+	ModuleLayoutNone        ModuleLayoutEnum = ""
+	ModuleLayoutService     ModuleLayoutEnum = "service"
+	ModuleLayoutCompartment ModuleLayoutEnum = "compartment"
+)
+
+const (
+	modulesDir     = "modules"
+	rootModuleFile = "main.tf"
+)
+
+var moduleVariableNameRegex = regexp.MustCompile(`[^a-zA-Z0-9\-\_]+`)
+
+// A root module that instantiates the modules generated from the discovered resources and wires them together
+type exportRootModule struct {
+	outputDir       string
+	compartment     *exportCompartment // The compartment whose outputs and remote states are generated in the root module, if any
+	modules         []*exportModule
+	vars            map[string]string
+	attributeVars   map[string]*attributeVar
+	resourceModules map[string]*exportModule // Maps the Terraform reference of each generated resource to its module
+}
+
+// A module generated from the discovered resources of one or more steps of a compartment
+type exportModule struct {
+	name                string
+	compartment         *exportCompartment
+	generateConfigSteps []*GenerateConfigStep
+	interpolationMap    map[string]string // The references used to generate the module's resources
+	inputs              map[string]string // Maps each input variable of the module to its value in the root module
+	outputs             map[string]string
+}
+
+func newExportRootModule(outputDir string) *exportRootModule {
+	return &exportRootModule{
+		outputDir:       outputDir,
+		vars:            map[string]string{},
+		attributeVars:   map[string]*attributeVar{},
+		resourceModules: map[string]*exportModule{},
+	}
+}
+
+func (root *exportRootModule) addModule(name string, compartment *exportCompartment, generateConfigSteps []*GenerateConfigStep) {
+	module := &exportModule{
+		name:                name,
+		compartment:         compartment,
+		generateConfigSteps: generateConfigSteps,
+		interpolationMap:    map[string]string{},
+		inputs:              map[string]string{},
+		outputs:             map[string]string{},
+	}
+
+	for _, step := range generateConfigSteps {
+		for _, resource := range step.discoveredResources {
+			root.resourceModules[resource.getTerraformReference()] = module
+		}
+	}
+	root.modules = append(root.modules, module)
+}
+
+// Generates the configuration of every exported compartment as modules.
+// For the service layout, each compartment gets a root module with a module for each service. For the compartment
+// layout, a single root module is generated with a module for each compartment.
+func generateModuleConfigs(clients *OracleClients, exportCompartments []*exportCompartment, args *ExportCommandArgs, region string) ([]string, error) {
+	rootModules := []*exportRootModule{}
+	if args.ModuleLayout == ModuleLayoutCompartment {
+		root := newExportRootModule(*args.OutputDir)
+		for _, compartment := range exportCompartments {
+			root.addModule(compartment.remoteStateName, compartment, compartment.generateConfigSteps)
+		}
+		rootModules = append(rootModules, root)
+	} else {
+		for _, compartment := range exportCompartments {
+			root := newExportRootModule(compartment.outputDir)
+			root.compartment = compartment
+			for _, step := range compartment.generateConfigSteps {
+				// Services without any discovered resources are left out, rather than generating empty modules
+				if len(step.discoveredResources) > 0 {
+					root.addModule(step.stepName, compartment, []*GenerateConfigStep{step})
+				}
+			}
+			rootModules = append(rootModules, root)
+		}
+	}
+
+	summaryStatements := []string{}
+	for idx, root := range rootModules {
+		// The output directory of the top compartment is expected to exist, but sub-compartment directories are created as needed
+		if idx > 0 {
+			if err := os.MkdirAll(root.outputDir, os.ModePerm); err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		root.resolveReferences()
+		root.vars["region"] = fmt.Sprintf("\"%s\"", region)
+
+		statements, err := root.generate(clients, args)
+		summaryStatements = append(summaryStatements, statements...)
+		if err != nil {
+			return summaryStatements, err
+		}
+	}
+
+	return summaryStatements, nil
+}
+
+// Replaces the references of each module to anything outside of the module with input variables, and adds the outputs
+// of other modules that are needed to set those inputs in the root module
+func (root *exportRootModule) resolveReferences() {
+	// Every module exposes the IDs of its resources
+	for _, module := range root.modules {
+		for _, step := range module.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				resourceReference := resource.getHclReferenceIdString()
+				module.outputs[getModuleVariableName(resourceReference)] = resourceReference
+			}
+		}
+	}
+
+	for _, module := range root.modules {
+		compartmentVars := map[string]string{}
+		for varName := range module.compartment.vars {
+			compartmentVars[tfHclVersion.getVarHclString(varName)] = varName
+		}
+
+		for _, step := range module.generateConfigSteps {
+			for _, resource := range step.discoveredResources {
+				values := []string{resource.compartmentId}
+				if resourceSchema, exists := resourcesMap[resource.terraformClass]; exists {
+					values = append(values, getConfigurableStringValues(resource.sourceAttributes, resourceSchema)...)
+				}
+
+				for _, value := range values {
+					interpolation, exists := module.compartment.referenceMap[value]
+					if !exists {
+						continue
+					}
+
+					if owner := root.resourceModules[getInterpolationResourceReference(interpolation)]; owner == module {
+						module.interpolationMap[value] = interpolation
+						continue
+					}
+
+					inputName, inputValue := root.getRootModuleValue(module, interpolation, compartmentVars)
+					module.inputs[inputName] = inputValue
+					module.interpolationMap[value] = tfHclVersion.getVarHclString(inputName)
+				}
+			}
+		}
+	}
+
+	if root.compartment != nil {
+		for outputName, outputValue := range root.compartment.outputs {
+			if owner, isModuleResource := root.resourceModules[getInterpolationResourceReference(outputValue)]; isModuleResource {
+				_, root.compartment.outputs[outputName] = root.getModuleOutput(owner, outputValue)
+			}
+		}
+	}
+}
+
+// Returns the name of the input variable used by a module for an interpolation outside of the module, along with the
+// value that the root module sets for that input
+func (root *exportRootModule) getRootModuleValue(module *exportModule, interpolation string, compartmentVars map[string]string) (string, string) {
+	if owner, isModuleResource := root.resourceModules[getInterpolationResourceReference(interpolation)]; isModuleResource {
+		return root.getModuleOutput(owner, interpolation)
+	}
+
+	if varName, isVar := compartmentVars[interpolation]; isVar {
+		// Modules of different compartments may have variables with the same name, but a different default value
+		rootVarName := varName
+		defaultValue := module.compartment.vars[varName]
+		if existingValue, exists := root.vars[rootVarName]; exists && existingValue != defaultValue {
+			rootVarName = fmt.Sprintf("%s_%s", module.name, varName)
+		}
+		root.vars[rootVarName] = defaultValue
+		if v, isAttributeVar := module.compartment.attributeVars[varName]; isAttributeVar {
+			root.attributeVars[rootVarName] = v
+		}
+
+		return varName, tfHclVersion.getVarHclString(rootVarName)
+	}
+
+	// Anything else, such as a terraform_remote_state output, can be used as-is by the root module
+	return getModuleVariableName(interpolation), interpolation
+}
+
+// Adds an output for the interpolation to the module that owns it, and returns the output name along with the
+// interpolation used to read the output in the root module
+func (root *exportRootModule) getModuleOutput(owner *exportModule, interpolation string) (string, string) {
+	outputName := getModuleVariableName(interpolation)
+	owner.outputs[outputName] = interpolation
+	return outputName, tfHclVersion.getDoubleExpHclString(fmt.Sprintf("module.%s", owner.name), outputName)
+}
+
+// Writes the configuration of the root module and all of its modules
+func (root *exportRootModule) generate(clients *OracleClients, args *ExportCommandArgs) ([]string, error) {
+	summaryStatements := []string{}
+	allDiscoveredResources := []*OCIResource{}
+	resourceModuleNames := map[*OCIResource]string{}
+	rootBuilder := &strings.Builder{}
+	rootBuilder.WriteString("## This configuration was generated by terraform-provider-oci\n\n")
+	rootConfig := newJsonConfig()
+
+	for _, module := range root.modules {
+		moduleDir := fmt.Sprintf("%s%s%s%s%s", root.outputDir, string(os.PathSeparator), modulesDir, string(os.PathSeparator), module.name)
+		if err := os.MkdirAll(moduleDir, os.ModePerm); err != nil {
+			return summaryStatements, err
+		}
+
+		moduleResources, statements, err := generateConfigFiles(moduleDir, module.generateConfigSteps, module.interpolationMap)
+		summaryStatements = append(summaryStatements, statements...)
+		if err != nil {
+			return summaryStatements, err
+		}
+
+		for _, resource := range moduleResources {
+			resourceModuleNames[resource] = module.name
+		}
+		allDiscoveredResources = append(allDiscoveredResources, moduleResources...)
+
+		inputNames := getSortedKeys(convertToObjectMap(module.inputs))
+		moduleVars := map[string]string{}
+		moduleAttributeVars := map[string]*attributeVar{}
+		for _, inputName := range inputNames {
+			moduleVars[inputName] = ""
+			if v, isAttributeVar := module.compartment.attributeVars[inputName]; isAttributeVar {
+				moduleAttributeVars[inputName] = v
+			}
+		}
+
+		if err := generateVarsFile(moduleVars, moduleAttributeVars, &moduleDir); err != nil {
+			return summaryStatements, err
+		}
+
+		if err := generateOutputsFile(moduleDir, module.outputs); err != nil {
+			return summaryStatements, err
+		}
+
+		if exportProviderAlias != "" {
+			if err := generateModuleProviderFile(moduleDir); err != nil {
+				return summaryStatements, err
+			}
+		}
+
+		rootBuilder.WriteString(fmt.Sprintf("module %s {\nsource = \"./%s/%s\"\n", module.name, modulesDir, module.name))
+		moduleBody := map[string]interface{}{"source": fmt.Sprintf("./%s/%s", modulesDir, module.name)}
+		for _, inputName := range inputNames {
+			rootBuilder.WriteString(fmt.Sprintf("%s = %s\n", inputName, module.inputs[inputName]))
+			moduleBody[inputName] = getJsonInterpolation(module.inputs[inputName])
+		}
+		if exportProviderAlias != "" {
+			// The module's resources use the same provider alias as the root module
+			provider := tfHclVersion.getProviderHclString("oci", exportProviderAlias)
+			rootBuilder.WriteString(fmt.Sprintf("providers = {\n%s = %s\n}\n", provider, provider))
+			moduleBody["providers"] = map[string]interface{}{fmt.Sprintf("oci.%s", exportProviderAlias): fmt.Sprintf("oci.%s", exportProviderAlias)}
+		}
+		rootBuilder.WriteString("}\n\n")
+		rootConfig.addBlock("module", moduleBody, module.name)
+	}
+
+	if exportFormat == ExportFormatJson {
+		if err := writeExportJsonFile(root.outputDir, getExportConfigFileName(rootModuleFile), rootConfig); err != nil {
+			return summaryStatements, err
+		}
+	} else if err := writeExportConfigFile(root.outputDir, rootModuleFile, rootBuilder.String()); err != nil {
+		return summaryStatements, err
+	}
+	summaryStatements = append(summaryStatements, fmt.Sprintf("Generated %d modules under '%s'", len(root.modules), root.outputDir))
+
+	if err := generateProviderFile(&root.outputDir); err != nil {
+		return summaryStatements, err
+	}
+
+	// The root module looks up the external references of its modules, and passes them in like any other input
+	externalDataSources := []*externalDataSource{}
+	seenDataSources := map[*externalDataSource]bool{}
+	for _, module := range root.modules {
+		for _, dataSource := range module.compartment.externalDataSources {
+			if !seenDataSources[dataSource] {
+				seenDataSources[dataSource] = true
+				externalDataSources = append(externalDataSources, dataSource)
+				for varName, defaultValue := range dataSource.vars {
+					root.vars[varName] = defaultValue
+				}
+			}
+		}
+	}
+
+	if len(externalDataSources) > 0 {
+		if err := generateExternalReferencesFile(root.outputDir, externalDataSources); err != nil {
+			return summaryStatements, err
+		}
+		summaryStatements = append(summaryStatements, fmt.Sprintf("Generated %d data sources for external references under '%s'", len(externalDataSources), root.outputDir))
+	}
+
+	if err := generateVarsFile(root.vars, root.attributeVars, &root.outputDir); err != nil {
+		return summaryStatements, err
+	}
+
+	if len(root.attributeVars) > 0 {
+		if err := generateTfvarsExampleFile(root.outputDir, root.attributeVars); err != nil {
+			return summaryStatements, err
+		}
+		summaryStatements = append(summaryStatements, getAttributeVarsStatements(root.outputDir, root.attributeVars)...)
+	}
+
+	if root.compartment != nil && len(root.compartment.outputs) > 0 {
+		if err := generateOutputsFile(root.outputDir, root.compartment.outputs); err != nil {
+			return summaryStatements, err
+		}
+	}
+
+	if root.compartment != nil && len(root.compartment.remoteStates) > 0 {
+		if err := generateRemoteStateFile(root.compartment); err != nil {
+			return summaryStatements, err
+		}
+	}
+
+	if args.GenerateState {
+		statements, err := generateStateFile(clients, root.outputDir, allDiscoveredResources, resourceModuleNames, args.Parallelism)
+		summaryStatements = append(summaryStatements, statements...)
+		if err != nil {
+			return summaryStatements, err
+		}
+	}
+
+	if args.GenerateImports {
+		generateConfigSteps := []*GenerateConfigStep{}
+		for _, module := range root.modules {
+			generateConfigSteps = append(generateConfigSteps, module.generateConfigSteps...)
+		}
+
+		statements, err := generateImportScripts(root.outputDir, generateConfigSteps, resourceModuleNames)
+		summaryStatements = append(summaryStatements, statements...)
+		if err != nil {
+			return summaryStatements, err
+		}
+	}
+
+	return summaryStatements, nil
+}
+
+// Returns the expression of an interpolation, without the wrapping used by v0.11 configurations
+func getInterpolationExpression(interpolation string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(interpolation, "\"${"), "}\"")
+}
+
+// Returns the Terraform reference of the resource or data source that an interpolation reads from
+// e.g. 'oci_core_vcn.export_vcn' for 'oci_core_vcn.export_vcn.id'
+func getInterpolationResourceReference(interpolation string) string {
+	expression := strings.TrimPrefix(getInterpolationExpression(interpolation), "data.")
+	parts := strings.SplitN(expression, ".", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s", parts[0], parts[1])
+}
+
+// Returns the name of the variable or output used to pass an interpolation between modules
+// e.g. 'oci_core_vcn_export_vcn_id' for 'oci_core_vcn.export_vcn.id'
+func getModuleVariableName(interpolation string) string {
+	return moduleVariableNameRegex.ReplaceAllString(getInterpolationExpression(interpolation), "_")
+}
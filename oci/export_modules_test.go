@@ -0,0 +1,155 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that the export command generates a root module that instantiates a module for each service or compartment
+func TestUnitRunExportCommand_modules(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+
+	tests := []struct {
+		moduleLayout    ModuleLayoutEnum
+		expectedModules []string
+	}{
+		{ModuleLayoutService, []string{"compartment_testing", "tenancy_testing"}},
+		{ModuleLayoutCompartment, []string{"export"}},
+	}
+
+	for _, test := range tests {
+		outputDir, err := os.Getwd()
+		outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+		if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+			t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+			t.Fail()
+		}
+
+		tfHclVersion = &TfHclVersion12{}
+		args := &ExportCommandArgs{
+			CompartmentId: &compartmentId,
+			Services:      []string{"compartment_testing", "tenancy_testing"},
+			OutputDir:     &outputDir,
+			GenerateState: false,
+			TFVersion:     &tfHclVersion,
+			ModuleLayout:  test.moduleLayout,
+		}
+
+		if err = RunExportCommand(args); err != nil {
+			t.Logf("(%s layout) export command failed due to err: %v", test.moduleLayout, err)
+			t.Fail()
+		}
+
+		rootModule, err := ioutil.ReadFile(fmt.Sprintf("%s%smain.tf", outputDir, string(os.PathSeparator)))
+		if err != nil {
+			t.Logf("(%s layout) no main.tf file generated", test.moduleLayout)
+			t.Fail()
+		}
+
+		for _, moduleName := range test.expectedModules {
+			if !strings.Contains(string(rootModule), fmt.Sprintf("module %s {", moduleName)) {
+				t.Logf("(%s layout) module '%s' is not instantiated in main.tf", test.moduleLayout, moduleName)
+				t.Fail()
+			}
+
+			for _, file := range []string{"vars.tf", "outputs.tf"} {
+				if _, err = os.Stat(fmt.Sprintf("%s%smodules%s%s%s%s", outputDir, string(os.PathSeparator), string(os.PathSeparator), moduleName, string(os.PathSeparator), file)); os.IsNotExist(err) {
+					t.Logf("(%s layout) no %s file generated for module '%s'", test.moduleLayout, file, moduleName)
+					t.Fail()
+				}
+			}
+		}
+
+		// The compartment OCID should be passed to the modules from the root module
+		if !strings.Contains(string(rootModule), "compartment_ocid = var.compartment_ocid") {
+			t.Logf("(%s layout) expected the compartment_ocid input to be set by the root module", test.moduleLayout)
+			t.Fail()
+		}
+
+		os.RemoveAll(outputDir)
+	}
+}
+
+// Test that references between modules are replaced with module inputs that are set from the outputs of other modules
+func TestUnitExportRootModule_resolveReferences(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	tfHclVersion = &TfHclVersion12{}
+
+	parent := &OCIResource{
+		compartmentId:    "ocid1.compartment.abc",
+		sourceAttributes: map[string]interface{}{"display_name": "parent"},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.parent.abc",
+			terraformClass: "oci_test_parent",
+			terraformName:  "export_parent",
+		},
+	}
+	child := &OCIResource{
+		compartmentId:    "ocid1.compartment.abc",
+		sourceAttributes: map[string]interface{}{"parent_id": parent.id},
+		TerraformResource: TerraformResource{
+			id:             "ocid1.child.abc",
+			terraformClass: "oci_test_child",
+			terraformName:  "export_child",
+		},
+	}
+
+	compartment := newExportCompartment("ocid1.compartment.abc", "export", "export")
+	compartment.vars = map[string]string{"compartment_ocid": "\"ocid1.compartment.abc\""}
+	compartment.referenceMap = map[string]string{
+		"ocid1.compartment.abc": "var.compartment_ocid",
+		parent.id:               parent.getHclReferenceIdString(),
+		child.id:                child.getHclReferenceIdString(),
+	}
+
+	root := newExportRootModule("export")
+	root.addModule("parents", compartment, []*GenerateConfigStep{{discoveredResources: []*OCIResource{parent}}})
+	root.addModule("children", compartment, []*GenerateConfigStep{{discoveredResources: []*OCIResource{child}}})
+	root.resolveReferences()
+
+	parentModule, childModule := root.modules[0], root.modules[1]
+	if childModule.inputs["oci_test_parent_export_parent_id"] != "module.parents.oci_test_parent_export_parent_id" {
+		t.Logf("expected the parent ID to be set from the parents module but got inputs: %v", childModule.inputs)
+		t.Fail()
+	}
+
+	if childModule.interpolationMap[parent.id] != "var.oci_test_parent_export_parent_id" {
+		t.Logf("expected the parent ID to be referenced by an input variable but got '%s'", childModule.interpolationMap[parent.id])
+		t.Fail()
+	}
+
+	if parentModule.outputs["oci_test_parent_export_parent_id"] != "oci_test_parent.export_parent.id" {
+		t.Logf("expected the parents module to output the parent ID but got outputs: %v", parentModule.outputs)
+		t.Fail()
+	}
+
+	if parentModule.inputs["compartment_ocid"] != "var.compartment_ocid" || root.vars["compartment_ocid"] != "\"ocid1.compartment.abc\"" {
+		t.Logf("expected the compartment_ocid input to be set from a root module variable but got inputs: %v", parentModule.inputs)
+		t.Fail()
+	}
+}
+
+func TestUnitGetInterpolationResourceReference(t *testing.T) {
+	tests := []struct {
+		interpolation string
+		want          string
+	}{
+		{"oci_core_vcn.export_vcn.id", "oci_core_vcn.export_vcn"},
+		{"\"${oci_core_vcn.export_vcn.default_route_table_id}\"", "oci_core_vcn.export_vcn"},
+		{"data.oci_identity_availability_domain.export_AD-1.name", "oci_identity_availability_domain.export_AD-1"},
+		{"var.compartment_ocid", ""},
+	}
+
+	for _, test := range tests {
+		if got := getInterpolationResourceReference(test.interpolation); got != test.want {
+			t.Errorf("getInterpolationResourceReference(%s) = %v, want %v", test.interpolation, got, test.want)
+		}
+	}
+}
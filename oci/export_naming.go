@@ -0,0 +1,168 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var terraformNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_\-]*$`)
+
+// Assigns the Terraform names of discovered resources. Resources are named after their entry in a name mapping file
+// from a previous export if there is one, so that names are stable across exports, and otherwise by a name template.
+type resourceNamingStrategy struct {
+	template    *template.Template
+	nameMapping map[string]string // Maps the IDs of resources to their Terraform names
+}
+
+// Returns the naming strategy for a name template and a name mapping file, either of which may be empty.
+// A name mapping file that doesn't exist yet is created by the export, see writeNameMapping.
+func newResourceNamingStrategy(nameTemplate string, nameMappingFile string) (*resourceNamingStrategy, error) {
+	naming := &resourceNamingStrategy{nameMapping: map[string]string{}}
+
+	if nameTemplate != "" {
+		// The 'tag' function is bound to the tags of each resource when the template is executed
+		tmpl, err := template.New("name").Option("missingkey=error").Funcs(getNameTemplateFuncs(nil)).Parse(nameTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("[ERROR] invalid name template '%s': %v", nameTemplate, err)
+		}
+		naming.template = tmpl
+	}
+
+	if nameMappingFile == "" {
+		return naming, nil
+	}
+
+	contents, err := ioutil.ReadFile(nameMappingFile)
+	if os.IsNotExist(err) {
+		return naming, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(contents, &naming.nameMapping); err != nil {
+		return nil, fmt.Errorf("[ERROR] unable to read the name mapping file '%s': %v", nameMappingFile, err)
+	}
+
+	for id, terraformName := range naming.nameMapping {
+		if !terraformNameRegex.MatchString(terraformName) {
+			return nil, fmt.Errorf("[ERROR] invalid Terraform name '%s' for '%s' in the name mapping file '%s'", terraformName, id, nameMappingFile)
+		}
+	}
+
+	return naming, nil
+}
+
+// Adds the names of the name mapping to the names that are in use, so that they are not generated for other resources.
+// This needs to be done whenever the resource name counts are reset.
+func (naming *resourceNamingStrategy) reserveNames() {
+	if naming == nil {
+		return
+	}
+
+	resourceNameCountLock.Lock()
+	defer resourceNameCountLock.Unlock()
+	for _, terraformName := range naming.nameMapping {
+		if _, exists := resourceNameCount[terraformName]; !exists {
+			resourceNameCount[terraformName] = 1
+		}
+	}
+}
+
+// Returns the Terraform name of a resource from the name mapping or the name template
+func (naming *resourceNamingStrategy) getTerraformName(resource *OCIResource) (string, error) {
+	if naming == nil {
+		return "", fmt.Errorf("no naming strategy")
+	}
+
+	if terraformName, exists := naming.nameMapping[resource.id]; exists {
+		return terraformName, nil
+	}
+
+	if naming.template == nil {
+		return "", fmt.Errorf("no name template")
+	}
+
+	tmpl, err := naming.template.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	builder := &strings.Builder{}
+	if err := tmpl.Funcs(getNameTemplateFuncs(resource.sourceAttributes)).Execute(builder, resource.sourceAttributes); err != nil {
+		return "", err
+	}
+
+	name := builder.String()
+	if strings.TrimSpace(name) == "" || strings.Contains(name, "<no value>") {
+		return "", fmt.Errorf("the name template has no value for the resource")
+	}
+	return getUniqueTerraformName(getNormalizedTerraformName(name)), nil
+}
+
+// Writes the Terraform names of the discovered resources to the name mapping file, along with the names of any other
+// resources that were in the file
+func (naming *resourceNamingStrategy) writeNameMapping(nameMappingFile string, exportCompartments []*exportCompartment) error {
+	nameMapping := map[string]string{}
+	for id, terraformName := range naming.nameMapping {
+		nameMapping[id] = terraformName
+	}
+
+	for _, compartment := range exportCompartments {
+		for _, step := range compartment.generateConfigSteps {
+			for _, resources := range [][]*OCIResource{step.discoveredResources, step.omittedResources} {
+				for _, resource := range resources {
+					if resource.id != "" && resource.terraformName != "" {
+						nameMapping[resource.id] = resource.terraformName
+					}
+				}
+			}
+		}
+	}
+
+	return writeJsonReportFile(filepath.Dir(nameMappingFile), filepath.Base(nameMappingFile), nameMapping)
+}
+
+// Returns the functions of name templates for a resource. The 'tag' function returns the value of a defined tag, e.g.
+// 'Ops.Name', or of a freeform tag if there is no such defined tag.
+func getNameTemplateFuncs(sourceAttributes map[string]interface{}) template.FuncMap {
+	return template.FuncMap{
+		"tag": func(key string) (string, error) {
+			for _, tagsAttribute := range []string{"defined_tags", "freeform_tags"} {
+				if tags, ok := sourceAttributes[tagsAttribute].(map[string]interface{}); ok {
+					if value, exists := tags[key].(string); exists {
+						return value, nil
+					}
+				}
+			}
+			return "", fmt.Errorf("tag '%s' not found", key)
+		},
+	}
+}
+
+// Adds a count suffix to a Terraform name that is already in use. The suffixed name may itself be in use, e.g. if it
+// was reserved by the name mapping, in which case the count is increased until the name is unique.
+func getUniqueTerraformName(terraformName string) string {
+	resourceNameCountLock.Lock()
+	defer resourceNameCountLock.Unlock()
+	count, resourceNameExists := resourceNameCount[terraformName]
+	if !resourceNameExists {
+		resourceNameCount[terraformName] = 1
+		return terraformName
+	}
+
+	uniqueName := fmt.Sprintf("%s_%d", terraformName, count)
+	for _, exists := resourceNameCount[uniqueName]; exists; _, exists = resourceNameCount[uniqueName] {
+		count++
+		uniqueName = fmt.Sprintf("%s_%d", terraformName, count)
+	}
+	resourceNameCount[terraformName] = count + 1
+	resourceNameCount[uniqueName] = 1
+	return uniqueName
+}
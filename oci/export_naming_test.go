@@ -0,0 +1,161 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitResourceNamingStrategy_template(t *testing.T) {
+	resourceNameCount = map[string]int{}
+	naming, err := newResourceNamingStrategy(`{{tag "Ops.Name"}}_{{.display_name}}`, "")
+	if err != nil {
+		t.Fatalf("unexpected error parsing the name template: %v", err)
+	}
+
+	newResource := func(sourceAttributes map[string]interface{}) *OCIResource {
+		return &OCIResource{sourceAttributes: sourceAttributes}
+	}
+
+	taggedAttributes := map[string]interface{}{
+		"display_name": "db",
+		"defined_tags": map[string]interface{}{"Ops.Name": "payments"},
+	}
+	name, err := naming.getTerraformName(newResource(taggedAttributes))
+	assert.NoError(t, err)
+	assert.Equal(t, "export_payments_db", name)
+
+	// Names are unique, and freeform tags are used if there is no defined tag
+	name, err = naming.getTerraformName(newResource(map[string]interface{}{
+		"display_name":  "db",
+		"freeform_tags": map[string]interface{}{"Ops.Name": "payments"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "export_payments_db_1", name)
+
+	// Resources without a tag or attribute in the template are not named by it
+	_, err = naming.getTerraformName(newResource(map[string]interface{}{"display_name": "db"}))
+	assert.Error(t, err)
+	_, err = naming.getTerraformName(newResource(map[string]interface{}{"defined_tags": map[string]interface{}{"Ops.Name": "payments"}}))
+	assert.Error(t, err)
+
+	if _, err := newResourceNamingStrategy("{{.display_name", ""); err == nil {
+		t.Errorf("expected an error for an invalid name template")
+	}
+}
+
+// Test that new names don't collide with suffixed names in the name mapping file
+func TestUnitResourceNamingStrategy_suffixedNameMapping(t *testing.T) {
+	nameMappingFile, err := ioutil.TempFile("", "name_mapping*.json")
+	if err != nil {
+		t.Fatalf("unable to create the name mapping file: %v", err)
+	}
+	defer os.Remove(nameMappingFile.Name())
+	nameMappingFile.WriteString(`{"ocid1.parent.a": "export_foo", "ocid1.parent.b": "export_foo_1", "ocid1.parent.c": "export_foo_3"}`)
+	nameMappingFile.Close()
+
+	resourceNameCount = map[string]int{}
+	naming, err := newResourceNamingStrategy("{{.display_name}}", nameMappingFile.Name())
+	if err != nil {
+		t.Fatalf("unexpected error reading the name mapping file: %v", err)
+	}
+	naming.reserveNames()
+
+	newResource := &OCIResource{sourceAttributes: map[string]interface{}{"display_name": "foo"}}
+	names := []string{}
+	for i := 0; i < 3; i++ {
+		name, err := naming.getTerraformName(newResource)
+		assert.NoError(t, err)
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"export_foo_2", "export_foo_4", "export_foo_5"}, names)
+
+	// The suffixed names are in use too
+	assert.Equal(t, "export_foo_2_1", getUniqueTerraformName("export_foo_2"))
+}
+
+// Test that the names of resources in the name mapping file are kept across exports
+func TestUnitRunExportCommand_nameMapping(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	nameMappingFile := fmt.Sprintf("%s%sname_mapping.json", outputDir, string(os.PathSeparator))
+	readNameMapping := func() map[string]string {
+		contents, err := ioutil.ReadFile(nameMappingFile)
+		if err != nil {
+			t.Fatalf("no name mapping file generated")
+		}
+
+		nameMapping := map[string]string{}
+		if err := json.Unmarshal(contents, &nameMapping); err != nil {
+			t.Fatalf("unable to read the name mapping file: %v", err)
+		}
+		return nameMapping
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId:   &compartmentId,
+		Services:        []string{"compartment_testing"},
+		OutputDir:       &outputDir,
+		TFVersion:       &tfHclVersion,
+		NameTemplate:    "parent_{{.display_name}}",
+		NameMappingFile: nameMappingFile,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	nameMapping := readNameMapping()
+	assert.Len(t, nameMapping, len(parentResources)+len(childrenResources))
+	assert.Equal(t, "export_parent_string0", nameMapping[getTestResourceId("parent", 0)])
+	assert.Equal(t, "export_parent_string0_child_1", nameMapping[getTestResourceId("child", 0)])
+
+	// Renamed resources keep their new names, and the template isn't used for resources that are already mapped
+	nameMapping[getTestResourceId("parent", 0)] = "renamed_parent"
+	nameMapping["ocid1.parent.deleted"] = "deleted_parent"
+	contents, _ := json.Marshal(nameMapping)
+	if err := ioutil.WriteFile(nameMappingFile, contents, 0666); err != nil {
+		t.Fatalf("unable to write the name mapping file: %v", err)
+	}
+
+	args.NameTemplate = "{{.display_name}}"
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	config, err := ioutil.ReadFile(fmt.Sprintf("%s%scompartment_testing.tf", outputDir, string(os.PathSeparator)))
+	if err != nil {
+		t.Fatalf("no compartment_testing.tf file generated")
+	}
+	assert.Contains(t, string(config), "resource oci_test_parent renamed_parent {")
+	assert.Contains(t, string(config), "resource oci_test_parent export_parent_string1 {")
+	assert.Equal(t, len(parentResources), strings.Count(string(config), "resource oci_test_parent"))
+
+	updatedNameMapping := readNameMapping()
+	assert.Equal(t, nameMapping, updatedNameMapping)
+
+	// Names in the mapping file must be valid Terraform names
+	updatedNameMapping[getTestResourceId("parent", 0)] = "invalid name"
+	contents, _ = json.Marshal(updatedNameMapping)
+	if err := ioutil.WriteFile(nameMappingFile, contents, 0666); err != nil {
+		t.Fatalf("unable to write the name mapping file: %v", err)
+	}
+	if err = RunExportCommand(args); err == nil {
+		t.Errorf("expected the export command to fail for an invalid name in the name mapping file")
+	}
+}
@@ -0,0 +1,178 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	oci_common "github.com/oracle/oci-go-sdk/common"
+	oci_identity "github.com/oracle/oci-go-sdk/identity"
+)
+
+// Alias of the provider that the generated resources use, when exporting multiple regions
+var exportProviderAlias = ""
+
+// Returns the name of the tenancy's home region. It's a variable so that tests can override it.
+var getHomeRegionFn = getHomeRegion
+
+var providerAliasRegex = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Exports the compartment from each of the regions into a sub-directory of the output directory named after the
+// region. The resources of each region use a provider alias for the region, and tenancy-scope resources are only
+// exported from the home region, which is exported along with the regions for them if it isn't one of the regions.
+func runRegionsExportCommand(clients *OracleClients, args *ExportCommandArgs) error {
+	if len(args.IDs) > 0 {
+		return fmt.Errorf("[ERROR] ids cannot be used when exporting multiple regions, since each resource belongs to a single region")
+	}
+
+	homeRegion, err := getHomeRegionFn(clients)
+	if err != nil {
+		return err
+	}
+
+	outputDir := *args.OutputDir
+	services := args.Services
+	defer func() {
+		args.OutputDir = &outputDir
+		args.Services = services
+		exportProviderAlias = ""
+	}()
+
+	regionArgs := []string{}
+	exportedRegions := map[string]bool{}
+	for _, regionArg := range args.Regions {
+		regionArg = strings.TrimSpace(regionArg)
+		region := string(oci_common.StringToRegion(regionArg))
+		if regionArg == "" || exportedRegions[region] {
+			continue
+		}
+		exportedRegions[region] = true
+		regionArgs = append(regionArgs, regionArg)
+	}
+
+	tenancyScopeServices := getTenancyScopeServices(services)
+	if !exportedRegions[homeRegion] && len(tenancyScopeServices) > 0 {
+		color.Yellow("Exporting tenancy-scope services %s from the home region '%s', which is not one of the regions", strings.Join(tenancyScopeServices, ", "), homeRegion)
+		regionArgs = append(regionArgs, homeRegion)
+	}
+
+	failedRegions := []string{}
+	for _, regionArg := range regionArgs {
+		region := string(oci_common.StringToRegion(regionArg))
+
+		regionServices := services
+		if region != homeRegion {
+			regionServices = getCompartmentScopeServices(services)
+			if len(services) > 0 && len(regionServices) == 0 {
+				log.Printf("[INFO] skip exporting region '%s' since only tenancy-scope resources were requested", region)
+				continue
+			}
+		} else if !exportedRegions[region] {
+			regionServices = tenancyScopeServices
+		}
+
+		regionOutputDir := fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), region)
+		if err := os.MkdirAll(regionOutputDir, os.ModePerm); err != nil {
+			return err
+		}
+
+		regionClients, err := getExportClients(region)
+		if err != nil {
+			return err
+		}
+
+		args.OutputDir = &regionOutputDir
+		args.Services = regionServices
+		exportProviderAlias = getRegionProviderAlias(regionArg)
+
+		color.Green("Exporting region '%s' to '%s'", region, regionOutputDir)
+		if err := runExportCommand(regionClients, args); err != nil {
+			if !args.ContinueOnError {
+				return fmt.Errorf("[ERROR] export of region '%s' failed: %v", region, err)
+			}
+			color.Red("%v", err)
+			failedRegions = append(failedRegions, region)
+		}
+	}
+
+	if len(failedRegions) > 0 {
+		return fmt.Errorf("[ERROR] export of regions %s failed", strings.Join(failedRegions, ", "))
+	}
+
+	return nil
+}
+
+// Returns the services without any tenancy-scope services
+func getCompartmentScopeServices(services []string) []string {
+	result := []string{}
+	for _, service := range services {
+		if _, isTenancyScope := tenancyResourceGraphs[service]; !isTenancyScope {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// Returns the tenancy-scope services among the services
+func getTenancyScopeServices(services []string) []string {
+	result := []string{}
+	for _, service := range services {
+		if _, isTenancyScope := tenancyResourceGraphs[service]; isTenancyScope {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+func getHomeRegion(clients *OracleClients) (string, error) {
+	tenancyId := clients.configuration[tenancyOcidAttrName]
+
+	response, err := clients.identityClient().ListRegionSubscriptions(context.Background(), oci_identity.ListRegionSubscriptionsRequest{
+		TenancyId: &tenancyId,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, subscription := range response.Items {
+		if subscription.IsHomeRegion != nil && *subscription.IsHomeRegion && subscription.RegionName != nil {
+			return *subscription.RegionName, nil
+		}
+	}
+
+	return "", fmt.Errorf("[ERROR] could not find the home region of the tenancy")
+}
+
+// Returns the provider alias of a region as given on the command line, e.g. 'phx' or 'us_phoenix_1'
+func getRegionProviderAlias(region string) string {
+	return providerAliasRegex.ReplaceAllString(strings.ToLower(region), "_")
+}
+
+// Returns the 'provider' meta-argument of the generated resources and data sources, if they use a provider alias
+func getProviderMetaArgumentHclString() string {
+	if exportProviderAlias == "" {
+		return ""
+	}
+	return fmt.Sprintf("provider = %s\n", tfHclVersion.getProviderHclString("oci", exportProviderAlias))
+}
+
+func addProviderMetaArgument(body map[string]interface{}) {
+	if exportProviderAlias != "" {
+		body["provider"] = fmt.Sprintf("oci.%s", exportProviderAlias)
+	}
+}
+
+// Writes the proxy provider block of a module, through which the root module passes in the aliased provider
+func generateModuleProviderFile(moduleDir string) error {
+	if exportFormat == ExportFormatJson {
+		config := newJsonConfig()
+		config.addBlock("provider", map[string]interface{}{"alias": exportProviderAlias}, "oci")
+		return writeExportJsonFile(moduleDir, getExportConfigFileName(providerFile), config)
+	}
+
+	return writeExportConfigFile(moduleDir, providerFile, fmt.Sprintf("provider oci {\n\talias = %q\n}\n", exportProviderAlias))
+}
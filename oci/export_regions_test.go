@@ -0,0 +1,90 @@
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that each region is exported to its own directory with an aliased provider, and that tenancy-scope resources
+// are only exported from the home region
+func TestUnitRunExportCommand_regions(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	getHomeRegion := getHomeRegionFn
+	defer func() { getHomeRegionFn = getHomeRegion }()
+	getHomeRegionFn = func(clients *OracleClients) (string, error) {
+		return "us-phoenix-1", nil
+	}
+
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	readFile := func(region string, fileName string) string {
+		contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s%s%s", outputDir, string(os.PathSeparator), region, string(os.PathSeparator), fileName))
+		if err != nil {
+			t.Fatalf("no %s file generated for region %s", fileName, region)
+		}
+		return string(contents)
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing", "tenancy_testing"},
+		Regions:       []string{"phx", "us-ashburn-1", "us-phoenix-1"},
+		OutputDir:     &outputDir,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+	assert.Equal(t, outputDir, *args.OutputDir)
+
+	assert.Contains(t, readFile("us-phoenix-1", providerFile), "alias = \"phx\"")
+	assert.Contains(t, readFile("us-phoenix-1", varsFile), "\"us-phoenix-1\"")
+	config := readFile("us-phoenix-1", "compartment_testing.tf")
+	assert.Equal(t, len(parentResources)+len(childrenResources), strings.Count(config, "provider = oci.phx\n"))
+	assert.Contains(t, readFile("us-phoenix-1", "tenancy_testing.tf"), "provider = oci.phx\n")
+
+	assert.Contains(t, readFile("us-ashburn-1", providerFile), "alias = \"us_ashburn_1\"")
+	assert.Contains(t, readFile("us-ashburn-1", varsFile), "\"us-ashburn-1\"")
+	assert.Contains(t, readFile("us-ashburn-1", "compartment_testing.tf"), "provider = oci.us_ashburn_1\n")
+	if _, err = os.Stat(fmt.Sprintf("%s%sus-ashburn-1%stenancy_testing.tf", outputDir, string(os.PathSeparator), string(os.PathSeparator))); !os.IsNotExist(err) {
+		t.Errorf("tenancy-scope resources should only be exported from the home region")
+	}
+
+	// The home region is exported for the tenancy-scope resources even if it isn't one of the regions
+	if err = os.RemoveAll(fmt.Sprintf("%s%sus-phoenix-1", outputDir, string(os.PathSeparator))); err != nil {
+		t.Fatalf("unable to remove the home region's output: %v", err)
+	}
+	args.Regions = []string{"iad"}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+	assert.Contains(t, readFile("us-ashburn-1", "compartment_testing.tf"), "provider = oci.iad\n")
+	assert.Contains(t, readFile("us-phoenix-1", providerFile), "alias = \"us_phoenix_1\"")
+	assert.Contains(t, readFile("us-phoenix-1", "tenancy_testing.tf"), "provider = oci.us_phoenix_1\n")
+	if _, err = os.Stat(fmt.Sprintf("%s%sus-phoenix-1%scompartment_testing.tf", outputDir, string(os.PathSeparator), string(os.PathSeparator))); !os.IsNotExist(err) {
+		t.Errorf("only tenancy-scope resources should be exported from a home region that isn't one of the regions")
+	}
+
+	// Resources outside of a multi-region export don't use a provider alias
+	args.Regions = nil
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+	contents, _ := ioutil.ReadFile(fmt.Sprintf("%s%scompartment_testing.tf", outputDir, string(os.PathSeparator)))
+	assert.NotContains(t, string(contents), "provider =")
+}
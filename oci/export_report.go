@@ -0,0 +1,245 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+const exportReportFile = "export_report.json"
+
+// A machine-readable report of an export run, written to the output directory of the export command
+type exportReport struct {
+	CompartmentId   string                  `json:"compartment_id"`
+	StartTime       string                  `json:"start_time"`
+	DurationSeconds float64                 `json:"duration_seconds"`
+	Succeeded       bool                    `json:"succeeded"`
+	Error           string                  `json:"error,omitempty"`
+	Totals          *exportReportTotals     `json:"totals"`
+	Services        []*exportReportService  `json:"services"`
+	Resources       []*exportReportResource `json:"resources"`
+	Skipped         []*exportReportSkipped  `json:"skipped"` // Resources that were skipped due to errors, with the continue on error option
+}
+
+type exportReportTotals struct {
+	Discovered                int `json:"discovered"`
+	Exported                  int `json:"exported"`
+	Omitted                   int `json:"omitted"`
+	MissingRequiredAttributes int `json:"missing_required_attributes"` // The number of resources with missing required attributes
+	Errors                    int `json:"errors"`                      // The number of resources with errors
+}
+
+// The totals and timings of a service, across all of the exported compartments
+type exportReportService struct {
+	Name string `json:"name"`
+	exportReportTotals
+	DiscoverySeconds  float64 `json:"discovery_seconds"`
+	GenerationSeconds float64 `json:"generation_seconds"`
+}
+
+type exportReportResource struct {
+	Service                   string   `json:"service"`
+	CompartmentId             string   `json:"compartment_id"`
+	Class                     string   `json:"class"`
+	TerraformName             string   `json:"terraform_name"`
+	Id                        string   `json:"id"`
+	ImportId                  string   `json:"import_id"`
+	OutputFile                string   `json:"output_file,omitempty"`
+	Omitted                   bool     `json:"omitted"`
+	MissingRequiredAttributes []string `json:"missing_required_attributes"`
+	Errors                    []string `json:"errors"`
+}
+
+// A child association whose resources, and any resources under them, were skipped due to an error
+type exportReportSkipped struct {
+	Service string `json:"service"`
+	Parent  string `json:"parent"`
+	Class   string `json:"class"`
+	Error   string `json:"error"`
+}
+
+func newExportReport(args *ExportCommandArgs, exportCompartments []*exportCompartment, compartmentTreeErrors []*resourceDiscoveryError, startTime time.Time, exportErr error) *exportReport {
+	report := &exportReport{
+		StartTime:       startTime.UTC().Format(time.RFC3339),
+		DurationSeconds: time.Since(startTime).Seconds(),
+		Succeeded:       exportErr == nil,
+		Totals:          &exportReportTotals{},
+		Services:        []*exportReportService{},
+		Resources:       []*exportReportResource{},
+		Skipped:         []*exportReportSkipped{},
+	}
+
+	if args.CompartmentId != nil {
+		report.CompartmentId = *args.CompartmentId
+	}
+
+	if exportErr != nil {
+		report.Error = exportErr.Error()
+	}
+
+	for _, discoveryError := range compartmentTreeErrors {
+		report.Skipped = append(report.Skipped, &exportReportSkipped{
+			Service: compartmentTreeStepName,
+			Parent:  discoveryError.parent,
+			Class:   discoveryError.resourceClass,
+			Error:   discoveryError.err.Error(),
+		})
+	}
+
+	services := map[string]*exportReportService{}
+	for _, compartment := range exportCompartments {
+		for _, step := range compartment.generateConfigSteps {
+			service, exists := services[step.stepName]
+			if !exists {
+				service = &exportReportService{Name: step.stepName}
+				services[step.stepName] = service
+				report.Services = append(report.Services, service)
+			}
+			service.DiscoverySeconds += step.discoveryDuration.Seconds()
+			service.GenerationSeconds += step.generationDuration.Seconds()
+
+			for _, resource := range step.discoveredResources {
+				reportResource := newExportReportResource(step, resource, false)
+				reportResource.OutputFile = step.configOutputFile
+				report.Resources = append(report.Resources, reportResource)
+				service.addResource(reportResource)
+				report.Totals.addResource(reportResource)
+			}
+
+			for _, resource := range step.omittedResources {
+				reportResource := newExportReportResource(step, resource, true)
+				report.Resources = append(report.Resources, reportResource)
+				service.addResource(reportResource)
+				report.Totals.addResource(reportResource)
+			}
+
+			for _, discoveryError := range step.discoveryErrors {
+				report.Skipped = append(report.Skipped, &exportReportSkipped{
+					Service: step.stepName,
+					Parent:  discoveryError.parent,
+					Class:   discoveryError.resourceClass,
+					Error:   discoveryError.err.Error(),
+				})
+			}
+		}
+	}
+
+	sort.Slice(report.Services, func(i, j int) bool {
+		return report.Services[i].Name < report.Services[j].Name
+	})
+
+	return report
+}
+
+func newExportReportResource(step *GenerateConfigStep, resource *OCIResource, omitted bool) *exportReportResource {
+	reportResource := &exportReportResource{
+		Service:                   step.stepName,
+		CompartmentId:             resource.compartmentId,
+		Class:                     resource.terraformClass,
+		TerraformName:             resource.terraformName,
+		Id:                        resource.id,
+		ImportId:                  resource.importId,
+		Omitted:                   omitted,
+		MissingRequiredAttributes: []string{},
+		Errors:                    []string{},
+	}
+
+	if reportResource.ImportId == "" {
+		reportResource.ImportId = resource.id
+	}
+
+	if resourceSchema, exists := resourcesMap[resource.terraformClass]; exists && !omitted {
+		// Required attributes that could not be discovered are replaced by variables, see generateAttributeVars
+		reportResource.MissingRequiredAttributes = append(getMissingRequiredAttributes(resource.sourceAttributes, resourceSchema, ""), resource.getUndiscoveredAttributes()...)
+		sort.Strings(reportResource.MissingRequiredAttributes)
+	}
+	reportResource.Errors = append(reportResource.Errors, resource.exportErrors...)
+
+	return reportResource
+}
+
+func (totals *exportReportTotals) addResource(resource *exportReportResource) {
+	totals.Discovered++
+	if resource.Omitted {
+		totals.Omitted++
+	} else {
+		totals.Exported++
+	}
+
+	if len(resource.MissingRequiredAttributes) > 0 {
+		totals.MissingRequiredAttributes++
+	}
+
+	if len(resource.Errors) > 0 {
+		totals.Errors++
+	}
+}
+
+// Returns the required attributes that could not be discovered, following the same rules as getHCLStringFromMap.
+// The attributes of nested blocks are prefixed with the name of the block, e.g. 'source_details.source_type'.
+func getMissingRequiredAttributes(sourceAttributes map[string]interface{}, resourceSchema *schema.Resource, prefix string) []string {
+	result := []string{}
+	for tfAttribute, tfSchema := range resourceSchema.Schema {
+		if tfSchema.Deprecated != "" || tfSchema.Removed != "" || (!tfSchema.Required && !tfSchema.Optional) {
+			continue
+		}
+
+		attributeVal, exists := sourceAttributes[tfAttribute]
+		if !exists || attributeVal == nil {
+			if tfSchema.Required {
+				result = append(result, prefix+tfAttribute)
+			}
+			continue
+		}
+
+		nestedResource, isNested := tfSchema.Elem.(*schema.Resource)
+		if !isNested {
+			continue
+		}
+
+		switch v := attributeVal.(type) {
+		case map[string]interface{}:
+			result = append(result, getMissingRequiredAttributes(v, nestedResource, fmt.Sprintf("%s%s.", prefix, tfAttribute))...)
+		case []interface{}:
+			for _, item := range v {
+				if block, ok := item.(map[string]interface{}); ok {
+					result = append(result, getMissingRequiredAttributes(block, nestedResource, fmt.Sprintf("%s%s.", prefix, tfAttribute))...)
+				}
+			}
+		}
+	}
+
+	sort.Strings(result)
+	uniqueResult := []string{}
+	for idx, attribute := range result {
+		// Nested blocks that are repeated may be missing the same attribute
+		if idx == 0 || attribute != result[idx-1] {
+			uniqueResult = append(uniqueResult, attribute)
+		}
+	}
+	return uniqueResult
+}
+
+func writeExportReport(outputDir string, report *exportReport) error {
+	return writeJsonReportFile(outputDir, exportReportFile, report)
+}
+
+// Writes a report as an indented JSON file to the output directory, replacing any existing file
+func writeJsonReportFile(outputDir string, fileName string, report interface{}) error {
+	contents, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpOutputFile := fmt.Sprintf("%s%s%s.tmp", outputDir, string(os.PathSeparator), fileName)
+	if err := ioutil.WriteFile(tmpOutputFile, contents, 0666); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpOutputFile, fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), fileName))
+}
@@ -0,0 +1,104 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the export command writes a report of the discovered resources, including for runs that fail
+func TestUnitRunExportCommand_report(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	readReport := func() *exportReport {
+		contents, err := ioutil.ReadFile(fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), exportReportFile))
+		if err != nil {
+			t.Fatalf("no %s file generated", exportReportFile)
+		}
+
+		report := &exportReport{}
+		if err := json.Unmarshal(contents, report); err != nil {
+			t.Fatalf("unable to read the export report: %v", err)
+		}
+		return report
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		Filters:       []string{"exclude:type=oci_test_child"},
+		OutputDir:     &outputDir,
+		TFVersion:     &tfHclVersion,
+	}
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	report := readReport()
+	assert.True(t, report.Succeeded)
+	assert.Equal(t, compartmentId, report.CompartmentId)
+	assert.Equal(t, &exportReportTotals{Discovered: 12, Exported: 4, Omitted: 8}, report.Totals)
+	if assert.Len(t, report.Services, 1) {
+		assert.Equal(t, "compartment_testing", report.Services[0].Name)
+		assert.Equal(t, 4, report.Services[0].Exported)
+	}
+
+	if assert.Len(t, report.Resources, 12) {
+		for _, resource := range report.Resources {
+			assert.Equal(t, resource.Class == "oci_test_child", resource.Omitted, "resource '%s'", resource.TerraformName)
+			assert.NotEmpty(t, resource.Id)
+			assert.Equal(t, resource.Id, resource.ImportId)
+			if !resource.Omitted {
+				assert.True(t, strings.HasSuffix(resource.OutputFile, "compartment_testing.tf"), "resource '%s' output file '%s'", resource.TerraformName, resource.OutputFile)
+			}
+		}
+	}
+
+	// A report is still written when the export fails
+	args.Filters = []string{"unknown_attribute=value"}
+	if err = RunExportCommand(args); err == nil {
+		t.Fatalf("expected the export command to fail for an invalid filter")
+	}
+
+	report = readReport()
+	assert.False(t, report.Succeeded)
+	assert.Contains(t, report.Error, "unknown_attribute")
+	assert.Empty(t, report.Resources)
+}
+
+func TestUnitGetMissingRequiredAttributes(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+
+	sourceAttributes := map[string]interface{}{
+		"display_name": "parent",
+		"a_nested": []interface{}{
+			map[string]interface{}{"nested_string": "nestedVal"},
+			map[string]interface{}{"nested_bool": true},
+			map[string]interface{}{"nested_bool": false},
+		},
+	}
+
+	// The parent schema requires compartment_id, but none of the nested attributes
+	assert.Equal(t, []string{"compartment_id"}, getMissingRequiredAttributes(sourceAttributes, resourcesMap["oci_test_parent"], ""))
+
+	sourceAttributes["compartment_id"] = "ocid1.compartment.abc"
+	assert.Empty(t, getMissingRequiredAttributes(sourceAttributes, resourcesMap["oci_test_parent"], ""))
+	assert.Equal(t, []string{"parent_id"}, getMissingRequiredAttributes(sourceAttributes, resourcesMap["oci_test_child"], ""))
+}
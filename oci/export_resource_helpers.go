@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	oci_core "github.com/oracle/oci-go-sdk/core"
 	oci_identity "github.com/oracle/oci-go-sdk/identity"
@@ -29,7 +30,8 @@ type TerraformResourceHints struct {
 	findResourcesOverrideFn func(*OracleClients, *TerraformResourceAssociation, *OCIResource) ([]*OCIResource, error)
 
 	// Hints to help with generating HCL representation from this resource
-	getHCLStringOverrideFn func(*strings.Builder, *OCIResource, map[string]string) error // Custom function for generating HCL syntax for the resource
+	getHCLStringOverrideFn  func(*strings.Builder, *OCIResource, map[string]string) error // Custom function for generating HCL syntax for the resource
+	addJsonConfigOverrideFn func(jsonConfig, *OCIResource, map[string]string) error       // Custom function for adding the resource to a JSON syntax configuration
 }
 
 type TerraformResourceAssociation struct {
@@ -49,6 +51,12 @@ type GenerateConfigStep struct {
 	stepName            string
 	discoveredResources []*OCIResource
 	omittedResources    []*OCIResource
+
+	// Details of the run that are written to the export report
+	configOutputFile   string
+	discoveryDuration  time.Duration
+	generationDuration time.Duration
+	discoveryErrors    []*resourceDiscoveryError // Errors that caused resources to be skipped, with the continue on error option
 }
 
 type TerraformResourceGraph map[string][]TerraformResourceAssociation
@@ -94,16 +102,31 @@ func init() {
 	exportIdentityAvailabilityDomainHints.alwaysExportable = true
 	exportIdentityAvailabilityDomainHints.processDiscoveredResourcesFn = processAvailabilityDomains
 	exportIdentityAvailabilityDomainHints.getHCLStringOverrideFn = getAvailabilityDomainHCLDatasource
+	exportIdentityAvailabilityDomainHints.addJsonConfigOverrideFn = addAvailabilityDomainJsonDatasource
 	exportIdentityAuthenticationPolicyHints.processDiscoveredResourcesFn = processIdentityAuthenticationPolicies
 	exportIdentityTagHints.findResourcesOverrideFn = findIdentityTags
 	exportIdentityTagHints.processDiscoveredResourcesFn = processTagDefinitions
 
 	exportObjectStorageNamespaceHints.processDiscoveredResourcesFn = processObjectStorageNamespace
 	exportObjectStorageNamespaceHints.getHCLStringOverrideFn = getObjectStorageNamespaceHCLDatasource
+	exportObjectStorageNamespaceHints.addJsonConfigOverrideFn = addObjectStorageNamespaceJsonDatasource
 	exportObjectStorageNamespaceHints.alwaysExportable = true
 
 	exportObjectStorageBucketHints.getIdFn = getObjectStorageBucketId
 
+	exportOnsNotificationTopicHints.getIdFn = getOnsNotificationTopicId
+
+	exportDnsRecordHints.getIdFn = getDnsRecordId
+	exportDnsRecordHints.processDiscoveredResourcesFn = processDnsRecords
+
+	exportFileStorageExportSetHints.processDiscoveredResourcesFn = processFileStorageExportSets
+
+	exportKmsVaultHints.processDiscoveredResourcesFn = processKmsVaults
+	exportKmsKeyHints.findResourcesOverrideFn = findKmsKeys
+	exportKmsKeyHints.processDiscoveredResourcesFn = processKmsKeys
+	exportKmsKeyVersionHints.getIdFn = getKmsKeyVersionId
+	exportKmsKeyVersionHints.processDiscoveredResourcesFn = processKmsKeyVersions
+
 	exportContainerengineNodePoolHints.processDiscoveredResourcesFn = processContainerengineNodePool
 }
 
@@ -141,7 +164,7 @@ func processInstances(clients *OracleClients, resources []*OCIResource) ([]*OCIR
 		// Ensure the boot volume created by this instance can be referenced elsewhere by adding it to the reference map
 		if bootVolumeId, exists := instance.sourceAttributes["boot_volume_id"]; exists {
 			if bootVolumeIdStr, ok := bootVolumeId.(string); ok {
-				referenceMap[bootVolumeIdStr] = tfHclVersion.getDoubleExpHclString(instance.getTerraformReference(), "boot_volume_id")
+				addReference(bootVolumeIdStr, tfHclVersion.getDoubleExpHclString(instance.getTerraformReference(), "boot_volume_id"))
 			}
 		}
 
@@ -152,9 +175,9 @@ func processInstances(clients *OracleClients, resources []*OCIResource) ([]*OCIR
 						sourceDetails["source_id"] = imageId
 
 						// The image OCID may be different if it's in a different tenancy or region, add a variable for users to specify
-						imageVarName := fmt.Sprintf("%s_source_image_id", instance.terraformName)
-						vars[imageVarName] = fmt.Sprintf("\"%s\"", imageId)
-						referenceMap[imageId] = tfHclVersion.getVarHclString(imageVarName)
+						imageVarName := getInstanceSourceImageVarName(instance)
+						addVar(imageVarName, fmt.Sprintf("\"%s\"", imageId))
+						addReference(imageId, tfHclVersion.getVarHclString(imageVarName))
 					}
 
 					// Workaround for service limitation. Service returns 47GB size for boot volume but LaunchInstance can only
@@ -179,6 +202,11 @@ func processInstances(clients *OracleClients, resources []*OCIResource) ([]*OCIR
 	return results, nil
 }
 
+// Returns the name of the variable for the source image of an instance, which is used instead of the image OCID
+func getInstanceSourceImageVarName(instance *OCIResource) string {
+	return fmt.Sprintf("%s_source_image_id", instance.terraformName)
+}
+
 func filterSecondaryVnicAttachments(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
 	results := []*OCIResource{}
 
@@ -241,7 +269,7 @@ func processAvailabilityDomains(clients *OracleClients, resources []*OCIResource
 		if !ok || adName == "" {
 			return resources, fmt.Errorf("[ERROR] availability domain at index '%v' has no name\n", idx)
 		}
-		referenceMap[adName] = tfHclVersion.getDataSourceHclString(ad.getTerraformReference(), "name")
+		addReference(adName, tfHclVersion.getDataSourceHclString(ad.getTerraformReference(), "name"))
 	}
 
 	return resources, nil
@@ -253,7 +281,7 @@ func processObjectStorageNamespace(clients *OracleClients, resources []*OCIResou
 		if !ok || namespaceName == "" {
 			return resources, fmt.Errorf("[ERROR] object storage namespace data source has no name\n")
 		}
-		referenceMap[namespaceName] = tfHclVersion.getDataSourceHclString(ns.getTerraformReference(), "namespace")
+		addReference(namespaceName, tfHclVersion.getDataSourceHclString(ns.getTerraformReference(), "namespace"))
 	}
 
 	return resources, nil
@@ -261,6 +289,7 @@ func processObjectStorageNamespace(clients *OracleClients, resources []*OCIResou
 
 func getAvailabilityDomainHCLDatasource(builder *strings.Builder, ociRes *OCIResource, varMap map[string]string) error {
 	builder.WriteString(fmt.Sprintf("data %s %s {\n", ociRes.terraformClass, ociRes.terraformName))
+	builder.WriteString(getProviderMetaArgumentHclString())
 
 	builder.WriteString(fmt.Sprintf("compartment_id = %v\n", varMap[ociRes.compartmentId]))
 
@@ -276,12 +305,39 @@ func getAvailabilityDomainHCLDatasource(builder *strings.Builder, ociRes *OCIRes
 
 func getObjectStorageNamespaceHCLDatasource(builder *strings.Builder, ociRes *OCIResource, varMap map[string]string) error {
 	builder.WriteString(fmt.Sprintf("data %s %s {\n", ociRes.terraformClass, ociRes.terraformName))
+	builder.WriteString(getProviderMetaArgumentHclString())
 	builder.WriteString(fmt.Sprintf("compartment_id = %v\n", varMap[ociRes.compartmentId]))
 	builder.WriteString("}\n")
 
 	return nil
 }
 
+func addAvailabilityDomainJsonDatasource(config jsonConfig, ociRes *OCIResource, varMap map[string]string) error {
+	adIndex, ok := ociRes.sourceAttributes["index"]
+	if !ok {
+		return fmt.Errorf("[ERROR] no index found for availability domain '%s'", ociRes.getTerraformReference())
+	}
+
+	body := map[string]interface{}{
+		"compartment_id": getJsonInterpolation(varMap[ociRes.compartmentId]),
+		"ad_number":      adIndex.(int),
+	}
+	addProviderMetaArgument(body)
+	config.addBlock("data", body, ociRes.terraformClass, ociRes.terraformName)
+
+	return nil
+}
+
+func addObjectStorageNamespaceJsonDatasource(config jsonConfig, ociRes *OCIResource, varMap map[string]string) error {
+	body := map[string]interface{}{
+		"compartment_id": getJsonInterpolation(varMap[ociRes.compartmentId]),
+	}
+	addProviderMetaArgument(body)
+	config.addBlock("data", body, ociRes.terraformClass, ociRes.terraformName)
+
+	return nil
+}
+
 func filterCustomImages(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
 	results := []*OCIResource{}
 
@@ -390,6 +446,149 @@ func processLoadBalancerCertificates(clients *OracleClients, resources []*OCIRes
 	return resources, nil
 }
 
+func getDnsRecordId(resource *OCIResource) (string, error) {
+	if resource.parent == nil {
+		return "", fmt.Errorf("[ERROR] unable to find zone for record id")
+	}
+
+	domain, ok := resource.sourceAttributes["domain"].(string)
+	if !ok {
+		return "", fmt.Errorf("[ERROR] unable to find domain for record id")
+	}
+
+	rtype, ok := resource.sourceAttributes["rtype"].(string)
+	if !ok {
+		return "", fmt.Errorf("[ERROR] unable to find rtype for record id")
+	}
+
+	recordHash, ok := resource.sourceAttributes["record_hash"].(string)
+	if !ok {
+		return "", fmt.Errorf("[ERROR] unable to find record hash for record id")
+	}
+
+	return getDnsRecordCompositeId(resource.parent.id, domain, rtype, recordHash), nil
+}
+
+func processDnsRecords(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
+	results := []*OCIResource{}
+
+	for _, record := range resources {
+		// Omit records that are managed by the service, such as the SOA and NS records at the apex of the zone
+		if isProtected, ok := record.sourceAttributes["is_protected"].(bool); ok && isProtected {
+			continue
+		}
+
+		domain, _ := record.sourceAttributes["domain"].(string)
+		rtype, _ := record.sourceAttributes["rtype"].(string)
+		zoneName, _ := record.parent.sourceAttributes["name"].(string)
+		if (strings.EqualFold(rtype, "SOA") || strings.EqualFold(rtype, "NS")) &&
+			strings.EqualFold(strings.TrimSuffix(domain, "."), strings.TrimSuffix(zoneName, ".")) {
+			continue
+		}
+
+		record.sourceAttributes["zone_name_or_id"] = record.parent.id
+		results = append(results, record)
+	}
+
+	return results, nil
+}
+
+func processFileStorageExportSets(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
+	// Export sets can't be created, they are managed through the mount target they belong to
+	for _, exportSet := range resources {
+		exportSet.sourceAttributes["mount_target_id"] = exportSet.parent.id
+	}
+	return resources, nil
+}
+
+func processKmsVaults(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
+	for _, vault := range resources {
+		// Keys and key versions are managed through the management endpoint of their vault
+		if managementEndpoint, ok := vault.sourceAttributes["management_endpoint"].(string); ok && managementEndpoint != "" {
+			addReference(managementEndpoint, tfHclVersion.getDoubleExpHclString(vault.getTerraformReference(), "management_endpoint"))
+		}
+	}
+	return resources, nil
+}
+
+func findKmsKeys(clients *OracleClients, tfMeta *TerraformResourceAssociation, parent *OCIResource) ([]*OCIResource, error) {
+	results, err := findResourcesGeneric(clients, tfMeta, parent)
+	if err != nil {
+		return results, err
+	}
+
+	managementEndpoint, ok := parent.sourceAttributes["management_endpoint"].(string)
+	if !ok {
+		return results, fmt.Errorf("[ERROR] unable to find management endpoint for vault '%s'", parent.id)
+	}
+
+	// The data source only returns key summaries, and keys can only be read through the management endpoint of their vault
+	keyResource := resourcesMap[tfMeta.resourceClass]
+	for _, key := range results {
+		d := keyResource.TestResourceData()
+		d.SetId(key.id)
+		d.Set("management_endpoint", managementEndpoint)
+		if err := keyResource.Read(d, clients); err != nil {
+			return results, err
+		}
+		key.sourceAttributes = convertResourceDataToMap(keyResource.Schema, d)
+	}
+
+	return results, nil
+}
+
+func processKmsKeys(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
+	for _, key := range resources {
+		key.importId = fmt.Sprintf("managementEndpoint/%s/keys/%s", key.sourceAttributes["management_endpoint"], key.id)
+	}
+	return resources, nil
+}
+
+func getKmsKeyVersionId(resource *OCIResource) (string, error) {
+	keyVersionId, ok := resource.sourceAttributes["key_version_id"].(string)
+	if !ok {
+		return "", fmt.Errorf("[ERROR] unable to find key version id for key version")
+	}
+
+	return getKeyVersionCompositeId(resource.parent.id, keyVersionId), nil
+}
+
+func processKmsKeyVersions(clients *OracleClients, resources []*OCIResource) ([]*OCIResource, error) {
+	// The first version of a key is created along with the key, so it is managed by the key resource
+	initialVersionIdx, initialTimeCreated := -1, ""
+	for idx, keyVersion := range resources {
+		timeCreated, _ := keyVersion.sourceAttributes["time_created"].(string)
+		if initialVersionIdx < 0 || timeCreated < initialTimeCreated {
+			initialVersionIdx, initialTimeCreated = idx, timeCreated
+		}
+	}
+
+	results := []*OCIResource{}
+	for idx, keyVersion := range resources {
+		if idx == initialVersionIdx {
+			continue
+		}
+
+		managementEndpoint := keyVersion.parent.sourceAttributes["management_endpoint"]
+		keyVersion.sourceAttributes["key_id"] = keyVersion.parent.id
+		keyVersion.sourceAttributes["management_endpoint"] = managementEndpoint
+		keyVersion.importId = fmt.Sprintf("managementEndpoint/%s/%s", managementEndpoint, keyVersion.id)
+		results = append(results, keyVersion)
+	}
+
+	return results, nil
+}
+
+func getOnsNotificationTopicId(resource *OCIResource) (string, error) {
+	// Notification topics are identified by their topic_id, which the data source doesn't return as the id
+	topicId, ok := resource.sourceAttributes["topic_id"].(string)
+	if !ok {
+		return "", fmt.Errorf("[ERROR] unable to find topic_id for notification topic")
+	}
+
+	return topicId, nil
+}
+
 func getObjectStorageBucketId(resource *OCIResource) (string, error) {
 	name, ok := resource.sourceAttributes["name"].(string)
 	if !ok {
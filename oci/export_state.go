@@ -0,0 +1,262 @@
+package oci
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/backend/local"
+	"github.com/hashicorp/terraform/config/hcl2shim"
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/plans/objchange"
+	"github.com/hashicorp/terraform/states"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/hashicorp/terraform/states/statemgr"
+)
+
+// Writes a Terraform state file with the given resources to the output directory.
+// The state is built from the discovered resources rather than importing each resource with Terraform, and resources
+// that can't be added to the state are listed in the returned summary statements instead of failing the export.
+// Resources that must be read again to build their state are read by the given number of workers in parallel.
+func generateStateFile(clients *OracleClients, outputDir string, resources []*OCIResource, resourceModules map[*OCIResource]string, parallelism int) ([]string, error) {
+	summaryStatements := []string{}
+	state := states.NewState()
+	providerAddr := addrs.ProviderConfig{Type: "oci", Alias: exportProviderAlias}.Absolute(addrs.RootModuleInstance)
+
+	stateObjects := make([]*states.ResourceInstanceObjectSrc, len(resources))
+	stateErrors := make([]error, len(resources))
+	newResourceDiscoveryPool(clients, parallelism).forEach(len(resources), func(idx int) {
+		if resourceSchema, exists := resourcesMap[resources[idx].terraformClass]; exists {
+			stateObjects[idx], stateErrors[idx] = getResourceStateObject(clients, resources[idx], resourceSchema)
+		}
+	})
+
+	failedResources := []string{}
+	importedCount := 0
+	for idx, resource := range resources {
+		log.Printf("[INFO] ===> Adding resource '%s' to the state", resource.getTerraformReference())
+
+		if _, exists := resourcesMap[resource.terraformClass]; !exists {
+			log.Printf("[INFO] skip adding '%s' to the state since it is not a Terraform OCI resource", resource.getTerraformReference())
+			continue
+		}
+
+		module := addrs.RootModuleInstance
+		resourceAddress := resource.getTerraformReference()
+		if moduleName, isModuleResource := resourceModules[resource]; isModuleResource {
+			module = module.Child(moduleName, addrs.NoKey)
+			resourceAddress = fmt.Sprintf("module.%s.%s", moduleName, resourceAddress)
+		}
+
+		stateObject, err := stateObjects[idx], stateErrors[idx]
+		if err != nil {
+			log.Printf("[WARN] unable to add '%s' to the state: %v", resourceAddress, err)
+			failedResources = append(failedResources, fmt.Sprintf("- %s (%s): %v", resourceAddress, resource.id, err))
+			resource.exportErrors = append(resource.exportErrors, fmt.Sprintf("unable to add the resource to the state file: %v", err))
+			continue
+		}
+
+		resourceAddr := addrs.Resource{
+			Mode: addrs.ManagedResourceMode,
+			Type: resource.terraformClass,
+			Name: resource.terraformName,
+		}
+		state.EnsureModule(module).SetResourceInstanceCurrent(resourceAddr.Instance(addrs.NoKey), stateObject, providerAddr)
+		importedCount++
+	}
+
+	if err := writeStateFile(outputDir, state); err != nil {
+		return summaryStatements, err
+	}
+
+	summaryStatements = append(summaryStatements, fmt.Sprintf("Added %d resources to the state file '%s%s%s'", importedCount, outputDir, string(os.PathSeparator), local.DefaultStateFilename))
+	if len(failedResources) > 0 {
+		summaryStatements = append(summaryStatements, "")
+		summaryStatements = append(summaryStatements, "Warning: The following resources could not be added to the state file.")
+		summaryStatements = append(summaryStatements, failedResources...)
+	}
+
+	return summaryStatements, nil
+}
+
+// Returns the state of a discovered resource
+func getResourceStateObject(clients *OracleClients, resource *OCIResource, resourceSchema *schema.Resource) (*states.ResourceInstanceObjectSrc, error) {
+	d, err := getResourceDataForState(clients, resource, resourceSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceState := d.State()
+	if instanceState == nil {
+		return nil, fmt.Errorf("[ERROR] resource no longer exists")
+	}
+	instanceState.Attributes["id"] = instanceState.ID
+
+	if resourceSchema.SchemaVersion > 0 {
+		if instanceState.Meta == nil {
+			instanceState.Meta = map[string]interface{}{}
+		}
+		instanceState.Meta["schema_version"] = strconv.Itoa(resourceSchema.SchemaVersion)
+	}
+
+	coreSchema := resourceSchema.CoreConfigSchema()
+	value, err := hcl2shim.HCL2ValueFromFlatmap(instanceState.Attributes, coreSchema.ImpliedType())
+	if err != nil {
+		return nil, err
+	}
+
+	stateObject := &states.ResourceInstanceObject{
+		Value:  objchange.NormalizeObjectFromLegacySDK(value, coreSchema),
+		Status: states.ObjectReady,
+	}
+
+	if len(instanceState.Meta) > 0 {
+		if stateObject.Private, err = json.Marshal(instanceState.Meta); err != nil {
+			return nil, err
+		}
+	}
+
+	return stateObject.Encode(coreSchema.ImpliedType(), uint64(resourceSchema.SchemaVersion))
+}
+
+// Returns the resource data of a discovered resource. Resources that were refreshed during discovery already have their
+// full state, and other resources get their state from the data source item that they were discovered with. Only
+// resources whose data source items lack attributes that the resource requires are read again.
+func getResourceDataForState(clients *OracleClients, resource *OCIResource, resourceSchema *schema.Resource) (*schema.ResourceData, error) {
+	if d, isRefreshed := resource.rawResource.(*schema.ResourceData); isRefreshed && resource.terraformTypeInfo != nil && resource.terraformTypeInfo.requireResourceRefresh {
+		return d, nil
+	}
+
+	if d, ok := getResourceDataFromSourceAttributes(resource, resourceSchema); ok {
+		return d, nil
+	}
+
+	log.Printf("[INFO] reading '%s' for the state since its discovered attributes are incomplete", resource.getTerraformReference())
+	return readResourceForState(clients, resource, resourceSchema)
+}
+
+// Maps the discovered attributes of a resource onto its resource schema. Returns false if the attributes lack any that
+// the resource requires, or if the resource is identified by an import ID that may differ from the ID in its state.
+func getResourceDataFromSourceAttributes(resource *OCIResource, resourceSchema *schema.Resource) (*schema.ResourceData, bool) {
+	if resource.id == "" || resource.sourceAttributes == nil || (resource.importId != "" && resource.importId != resource.id) {
+		return nil, false
+	}
+
+	attributes := getStateAttributes(resource.sourceAttributes, resourceSchema.Schema)
+	for name, attributeSchema := range resourceSchema.Schema {
+		if _, exists := attributes[name]; attributeSchema.Required && !exists {
+			return nil, false
+		}
+	}
+
+	d := resourceSchema.TestResourceData()
+	d.SetId(resource.id)
+	for name, value := range attributes {
+		if err := d.Set(name, value); err != nil {
+			log.Printf("[WARN] unable to set '%s' of '%s' from its discovered attributes: %v", name, resource.getTerraformReference(), err)
+			return nil, false
+		}
+	}
+	return d, true
+}
+
+// Returns the discovered attributes that are in the schema, leaving out attributes that were replaced by references
+// or variables in the configuration, since those don't hold the value of the attribute
+func getStateAttributes(sourceAttributes map[string]interface{}, attributesSchema map[string]*schema.Schema) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, value := range sourceAttributes {
+		attributeSchema, exists := attributesSchema[name]
+		if !exists || name == "id" {
+			continue
+		}
+		if stateValue, ok := getStateValue(value, attributeSchema); ok {
+			result[name] = stateValue
+		}
+	}
+	return result
+}
+
+func getStateValue(value interface{}, attributeSchema *schema.Schema) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil, InterpolationString:
+		return nil, false
+	case string:
+		// Attribute variables are set to a placeholder, see addAttributeVars
+		return v, !(strings.HasPrefix(v, "<<") && strings.HasSuffix(v, ">>"))
+	case []interface{}:
+		elemResource, isNested := attributeSchema.Elem.(*schema.Resource)
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if itemMap, isMap := item.(map[string]interface{}); isMap && isNested {
+				result = append(result, getStateAttributes(itemMap, elemResource.Schema))
+			} else if stateItem, ok := getStateValue(item, attributeSchema); ok {
+				result = append(result, stateItem)
+			}
+		}
+		return result, true
+	}
+	return value, true
+}
+
+// Reads a resource the same way as 'terraform import', using its import ID
+func readResourceForState(clients *OracleClients, resource *OCIResource, resourceSchema *schema.Resource) (*schema.ResourceData, error) {
+	if resourceSchema.Importer == nil {
+		return nil, fmt.Errorf("[ERROR] import is not supported for '%s'", resource.terraformClass)
+	}
+
+	importId := resource.importId
+	if len(importId) == 0 {
+		importId = resource.id
+	}
+
+	d := resourceSchema.TestResourceData()
+	d.SetId(importId)
+
+	// The clients were created for the export command, see getExportConfig
+	if resourceSchema.Importer.State != nil {
+		importedData, err := resourceSchema.Importer.State(d, clients)
+		if err != nil {
+			return nil, err
+		}
+		if len(importedData) != 1 {
+			return nil, fmt.Errorf("[ERROR] import of '%s' returned %d resources", importId, len(importedData))
+		}
+		d = importedData[0]
+	}
+
+	if err := resourceSchema.Read(d, clients); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Writes the state to the output directory, replacing any existing state file
+func writeStateFile(outputDir string, state *states.State) error {
+	buffer := &bytes.Buffer{}
+	if err := statefile.Write(statefile.New(state, statemgr.NewLineage(), 1), buffer); err != nil {
+		return err
+	}
+
+	tmpStateOutputFile := fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), defaultTmpStateFile)
+	stateOutputFile := fmt.Sprintf("%s%s%s", outputDir, string(os.PathSeparator), local.DefaultStateFilename)
+	file, err := os.OpenFile(tmpStateOutputFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(buffer.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpStateOutputFile, stateOutputFile)
+}
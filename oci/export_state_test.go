@@ -0,0 +1,138 @@
+package oci
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/states/statefile"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test that the export command writes a state file with the discovered resources
+func TestUnitRunExportCommand_generateState(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	compartmentId := resourceDiscoveryTestCompartmentOcid
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	// Parents are discovered with all of their attributes, so their state is built without reading them again
+	var parentReads int32
+	resourcesMap["oci_test_parent"].Read = func(d *schema.ResourceData, m interface{}) error {
+		atomic.AddInt32(&parentReads, 1)
+		return readTestParent(d, m)
+	}
+
+	tfHclVersion = &TfHclVersion12{}
+	args := &ExportCommandArgs{
+		CompartmentId: &compartmentId,
+		Services:      []string{"compartment_testing"},
+		OutputDir:     &outputDir,
+		GenerateState: true,
+		TFVersion:     &tfHclVersion,
+		Parallelism:   2,
+	}
+
+	if err = RunExportCommand(args); err != nil {
+		t.Fatalf("export command failed due to err: %v", err)
+	}
+
+	stateFile := fmt.Sprintf("%s%sterraform.tfstate", outputDir, string(os.PathSeparator))
+	file, err := os.Open(stateFile)
+	if err != nil {
+		t.Fatalf("no state file generated: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := statefile.Read(file); err != nil {
+		t.Errorf("state file can't be read by Terraform: %v", err)
+	}
+
+	state, err := readTerraformState(stateFile)
+	if err != nil {
+		t.Fatalf("unable to read the state file: %v", err)
+	}
+
+	instances := map[string]map[string]interface{}{}
+	for _, resource := range state.Resources {
+		assert.Equal(t, "managed", resource.Mode)
+		for idx := range resource.Instances {
+			instances[resource.getAddress(&resource.Instances[idx])] = resource.Instances[idx].Attributes
+		}
+	}
+	assert.Len(t, instances, 12)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&parentReads))
+	if parent, exists := instances["oci_test_parent.export_string0"]; assert.True(t, exists) {
+		assert.Equal(t, getTestResourceId("parent", 0), parent["id"])
+		assert.Equal(t, "string0", parent["compartment_id"])
+	}
+	if parent, exists := instances["oci_test_parent.export_string3"]; assert.True(t, exists) {
+		assert.Equal(t, "string3", parent["a_string"])
+		assert.Len(t, parent["a_nested"], 3)
+	}
+
+	// Children are refreshed during discovery, so their state has the attributes that the data source doesn't return
+	if child, exists := instances["oci_test_child.export_string3_child_1"]; assert.True(t, exists) {
+		assert.Equal(t, getTestResourceId("parent", 3), child["parent_id"])
+		assert.Len(t, child["a_nested"], 3)
+	}
+}
+
+// Test that resources that can't be added to the state are reported without failing the state generation
+func TestUnitGenerateStateFile_failedResources(t *testing.T) {
+	initResourceDiscoveryTests()
+	defer cleanupResourceDiscoveryTests()
+	outputDir, err := os.Getwd()
+	outputDir = fmt.Sprintf("%s%sdiscoveryTest-%d", outputDir, string(os.PathSeparator), time.Now().Nanosecond())
+	if err = os.Mkdir(outputDir, os.ModePerm); err != nil {
+		t.Logf("unable to mkdir %s. err: %v", outputDir, err)
+		t.Fail()
+	}
+	defer os.RemoveAll(outputDir)
+
+	resources := []*OCIResource{
+		{
+			TerraformResource: TerraformResource{
+				id:             getTestResourceId("parent", 0),
+				terraformClass: "oci_test_parent",
+				terraformName:  "export_parent",
+			},
+		},
+		{
+			TerraformResource: TerraformResource{
+				id:             getTestResourceId("parent", 99),
+				terraformClass: "oci_test_parent",
+				terraformName:  "export_deleted_parent",
+			},
+		},
+	}
+
+	statements, err := generateStateFile(oracleClients, outputDir, resources, map[*OCIResource]string{resources[0]: "parents"}, 2)
+	if err != nil {
+		t.Fatalf("generateStateFile failed due to err: %v", err)
+	}
+
+	summary := strings.Join(statements, "\n")
+	assert.Contains(t, summary, "Added 1 resources")
+	assert.Contains(t, summary, "oci_test_parent.export_deleted_parent")
+
+	state, err := readTerraformState(fmt.Sprintf("%s%sterraform.tfstate", outputDir, string(os.PathSeparator)))
+	if err != nil {
+		t.Fatalf("unable to read the state file: %v", err)
+	}
+	if assert.Len(t, state.Resources, 1) {
+		assert.Equal(t, "module.parents", state.Resources[0].Module)
+		assert.Equal(t, "export_parent", state.Resources[0].Name)
+	}
+}
@@ -12,7 +12,7 @@ import (
 func kmsGetRetryPolicy(disableNotFoundRetries bool, service string, optionals ...interface{}) *oci_common.RetryPolicy {
 	startTime := time.Now()
 	retryPolicy := &oci_common.RetryPolicy{
-		MaximumNumberAttempts: 0,
+		MaximumNumberAttempts: getServiceRetryPolicy(service).maxAttempts,
 		ShouldRetryOperation: func(response oci_common.OCIOperationResponse) bool {
 			return shouldRetry(response, disableNotFoundRetries, service, startTime, optionals...)
 		},
@@ -51,6 +51,8 @@ const (
 	authAPIKeySetting                     = "ApiKey"
 	authInstancePrincipalSetting          = "InstancePrincipal"
 	authInstancePrincipalWithCertsSetting = "InstancePrincipalWithCerts"
+	authSecurityTokenSetting              = "SecurityToken"
+	authResourcePrincipalSetting          = "ResourcePrincipal"
 	requestHeaderOpcOboToken              = "opc-obo-token"
 	requestHeaderOpcHostSerial            = "opc-host-serial"
 	defaultRequestTimeout                 = 0
@@ -75,6 +77,9 @@ const (
 	regionAttrName               = "region"
 	disableAutoRetriesAttrName   = "disable_auto_retries"
 	retryDurationSecondsAttrName = "retry_duration_seconds"
+	retryAttrName                = "retry"
+	rateLimitAttrName            = "rate_limit"
+	requestLogFileAttrName       = "request_log_file"
 	oboTokenAttrName             = "obo_token"
 	configFileProfileAttrName    = "config_file_profile"
 
@@ -114,7 +119,7 @@ func ociVarName(attrName string) string {
 
 func init() {
 	descriptions = map[string]string{
-		authAttrName:        fmt.Sprintf("(Optional) The type of auth to use. Options are '%s' and '%s'. By default, '%s' will be used.", authAPIKeySetting, authInstancePrincipalSetting, authAPIKeySetting),
+		authAttrName:        fmt.Sprintf("(Optional) The type of auth to use. Options are '%s', '%s', '%s' and '%s'. By default, '%s' will be used.", authAPIKeySetting, authInstancePrincipalSetting, authSecurityTokenSetting, authResourcePrincipalSetting, authAPIKeySetting),
 		tenancyOcidAttrName: fmt.Sprintf("(Optional) The tenancy OCID for a user. The tenancy OCID can be found at the bottom of user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", authAPIKeySetting),
 		userOcidAttrName:    fmt.Sprintf("(Optional) The user OCID. This can be found in user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", authAPIKeySetting),
 		fingerprintAttrName: fmt.Sprintf("(Optional) The fingerprint for the user's RSA key. This can be found in user settings in the Oracle Cloud Infrastructure console. Required if auth is set to '%s', ignored otherwise.", authAPIKeySetting),
@@ -128,15 +133,21 @@ func init() {
 			"Automatic retries were introduced to solve some eventual consistency problems but it also introduced performance issues on destroy operations.",
 		retryDurationSecondsAttrName: "(Optional) The minimum duration (in seconds) to retry a resource operation in response to an error.\n" +
 			"The actual retry duration may be longer due to jittering of retry operations. This value is ignored if the `disable_auto_retries` field is set to true.",
+		retryAttrName: "(Optional) The retry policy of a service, or of all services if no service is specified.\n" +
+			"The policy of a service overrides the policy of all services, which overrides the built-in retry behaviour. This block is ignored if the `disable_auto_retries` field is set to true.",
+		rateLimitAttrName: "(Optional) The client-side rate limits of a service, or of all services if no service is specified.\n" +
+			"The limits of a service override the limits of all services. Requests are held back when a service responds with a 429 status and a Retry-After header.",
+		requestLogFileAttrName: "(Optional) The path to a file that every API request is logged to, as a line of JSON.\n" +
+			"Authorization headers and secret fields, such as passwords and private keys, are redacted.",
 		configFileProfileAttrName: "(Optional) The profile name to be used from config file, if not set it will be DEFAULT.",
 	}
 }
 
 func Provider() terraform.ResourceProvider {
 	ociProvider = &schema.Provider{
-		DataSourcesMap: DataSourcesMap(),
+		DataSourcesMap: withRequestLogOperations(DataSourcesMap()),
 		Schema:         schemaMap(),
-		ResourcesMap:   ResourcesMap(),
+		ResourcesMap:   withRequestLogOperations(ResourcesMap()),
 		ConfigureFunc:  ProviderConfig,
 	}
 	return ociProvider
@@ -149,7 +160,7 @@ func schemaMap() map[string]*schema.Schema {
 			Optional:     true,
 			Description:  descriptions[authAttrName],
 			DefaultFunc:  schema.MultiEnvDefaultFunc([]string{tfVarName(authAttrName), ociVarName(authAttrName)}, authAPIKeySetting),
-			ValidateFunc: validation.StringInSlice([]string{authAPIKeySetting, authInstancePrincipalSetting, authInstancePrincipalWithCertsSetting}, true),
+			ValidateFunc: validation.StringInSlice([]string{authAPIKeySetting, authInstancePrincipalSetting, authInstancePrincipalWithCertsSetting, authSecurityTokenSetting, authResourcePrincipalSetting}, true),
 		},
 		tenancyOcidAttrName: {
 			Type:        schema.TypeString,
@@ -211,6 +222,92 @@ func schemaMap() map[string]*schema.Schema {
 			Description: descriptions[retryDurationSecondsAttrName],
 			DefaultFunc: schema.MultiEnvDefaultFunc([]string{tfVarName(retryDurationSecondsAttrName), ociVarName(retryDurationSecondsAttrName)}, nil),
 		},
+		retryAttrName: {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: descriptions[retryAttrName],
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"service": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"max_attempts": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+					"max_duration_seconds": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+					"base_backoff_seconds": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+					"max_backoff_seconds": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+					"retryable_status_codes": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Schema{
+							Type:         schema.TypeString,
+							ValidateFunc: validation.StringMatch(retryableStatusCodeRegex, "must be a 4xx or 5xx HTTP status other than 404, such as '429' or '5xx'. 404 errors are retried by retry_not_found_after_create"),
+						},
+					},
+					"retryable_conflict_codes": {
+						Type:     schema.TypeList,
+						Optional: true,
+						Elem: &schema.Schema{
+							Type: schema.TypeString,
+						},
+					},
+					"retry_not_found_after_create": {
+						Type:     schema.TypeBool,
+						Optional: true,
+					},
+				},
+			},
+		},
+		rateLimitAttrName: {
+			Type:        schema.TypeList,
+			Optional:    true,
+			Description: descriptions[rateLimitAttrName],
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"service": {
+						Type:     schema.TypeString,
+						Optional: true,
+					},
+					"requests_per_second": {
+						Type:         schema.TypeFloat,
+						Optional:     true,
+						ValidateFunc: validation.FloatBetween(0, math.MaxFloat64),
+					},
+					"burst": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+					"max_in_flight": {
+						Type:         schema.TypeInt,
+						Optional:     true,
+						ValidateFunc: validation.IntAtLeast(0),
+					},
+				},
+			},
+		},
+		requestLogFileAttrName: {
+			Type:        schema.TypeString,
+			Optional:    true,
+			Description: descriptions[requestLogFileAttrName],
+			DefaultFunc: schema.MultiEnvDefaultFunc([]string{tfVarName(requestLogFileAttrName), ociVarName(requestLogFileAttrName)}, nil),
+		},
 		configFileProfileAttrName: {
 			Type:        schema.TypeString,
 			Optional:    true,
@@ -332,15 +429,31 @@ func ProviderConfig(d *schema.ResourceData) (interface{}, error) {
 	if d.Get(disableAutoRetriesAttrName).(bool) {
 		shortRetryTime = 0
 		longRetryTime = 0
-	} else if retryDurationSeconds, exists := d.GetOkExists(retryDurationSecondsAttrName); exists {
-		val := time.Duration(retryDurationSeconds.(int)) * time.Second
-		if retryDurationSeconds.(int) < 0 {
-			// Retry for maximum amount of time, if a negative value was specified
-			val = time.Duration(math.MaxInt64)
+	} else {
+		if retryDurationSeconds, exists := d.GetOkExists(retryDurationSecondsAttrName); exists {
+			val := time.Duration(retryDurationSeconds.(int)) * time.Second
+			if retryDurationSeconds.(int) < 0 {
+				// Retry for maximum amount of time, if a negative value was specified
+				val = time.Duration(math.MaxInt64)
+			}
+			configuredRetryDuration = &val
+		}
+
+		retryPolicies, err := getConfiguredRetryPolicies(d)
+		if err != nil {
+			return nil, err
 		}
-		configuredRetryDuration = &val
+		configuredRetryPolicies = retryPolicies
 	}
 
+	rateLimits, err := getConfiguredRateLimits(d)
+	if err != nil {
+		return nil, err
+	}
+	configuredRateLimits = rateLimits
+
+	configuredRequestLogFile, _ = d.Get(requestLogFileAttrName).(string)
+
 	sdkConfigProvider, err := getSdkConfigProvider(d, clients)
 	if err != nil {
 		return nil, err
@@ -398,6 +511,12 @@ func getSdkConfigProvider(d *schema.ResourceData, clients *OracleClients) (oci_c
 		return nil, err
 	}
 
+	for _, configProvider := range configProviders {
+		if securityTokenProvider, ok := configProvider.(*securityTokenConfigProvider); ok {
+			return &securityTokenComposingConfigProvider{ConfigurationProvider: sdkConfigProvider, securityToken: securityTokenProvider}, nil
+		}
+	}
+
 	return sdkConfigProvider, nil
 }
 
@@ -488,9 +607,43 @@ func getConfigProviders(d *schema.ResourceData, auth string) ([]oci_common.Confi
 		}
 		log.Printf("[DEBUG] Configuration provided by: %s", cfg)
 
+		configProviders = append(configProviders, cfg)
+	case strings.ToLower(authSecurityTokenSetting):
+		apiKeyConfigVariablesToUnset, ok := checkIncompatibleAttrsForApiKeyAuth(d)
+		if !ok {
+			return nil, fmt.Errorf(`user credentials %v should be removed from the configuration`, strings.Join(apiKeyConfigVariablesToUnset, ", "))
+		}
+
+		profile, _ := d.Get(configFileProfileAttrName).(string)
+		cfg, err := newSecurityTokenConfigProvider(path.Join(getHomeFolder(), defaultConfigDirName, defaultConfigFileName), profile)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fail early if the security token has already expired
+		if _, err := cfg.KeyID(); err != nil {
+			return nil, err
+		}
+		log.Printf("[DEBUG] Configuration provided by: %s", cfg)
+
+		configProviders = append(configProviders, cfg)
+	case strings.ToLower(authResourcePrincipalSetting):
+		apiKeyConfigVariablesToUnset, ok := checkIncompatibleAttrsForApiKeyAuth(d)
+		if !ok {
+			return nil, fmt.Errorf(`user credentials %v should be removed from the configuration`, strings.Join(apiKeyConfigVariablesToUnset, ", "))
+		}
+
+		// The region of the resource principal is used, unless a region is configured
+		region, _ := d.Get(regionAttrName).(string)
+		cfg, err := newResourcePrincipalConfigProvider(region)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("[DEBUG] Configuration provided by: %s", cfg)
+
 		configProviders = append(configProviders, cfg)
 	default:
-		return nil, fmt.Errorf("auth must be one of '%s' or '%s' or '%s'", authAPIKeySetting, authInstancePrincipalSetting, authInstancePrincipalWithCertsSetting)
+		return nil, fmt.Errorf("auth must be one of '%s' or '%s' or '%s' or '%s' or '%s'", authAPIKeySetting, authInstancePrincipalSetting, authInstancePrincipalWithCertsSetting, authSecurityTokenSetting, authResourcePrincipalSetting)
 	}
 
 	return configProviders, nil
@@ -536,6 +689,12 @@ func buildConfigureClientFn(configProvider oci_common.ConfigurationProvider, htt
 		oboTokenProvider = oboTokenProviderFromEnv{}
 	}
 
+	rateLimitClient := getRateLimitClientFn()
+	requestLogClient, err := getRequestLogClientFn()
+	if err != nil {
+		return nil, err
+	}
+
 	configureClientFn := func(client *oci_common.BaseClient) error {
 		client.HTTPClient = httpClient
 		client.UserAgent = userAgent
@@ -596,6 +755,10 @@ func buildConfigureClientFn(configProvider oci_common.ConfigurationProvider, htt
 			}
 		}
 
+		// log requests to the request_log_file, and hold back requests to services that have client-side rate limits
+		requestLogClient(client)
+		rateLimitClient(client)
+
 		return nil
 	}
 
@@ -36,9 +36,13 @@ type OracleClients struct {
 	clientMap                 map[string]*OracleClient
 	gatewayWorkRequestsClient *oci_apigateway.WorkRequestsClient
 	workRequestClient         *oci_work_requests.WorkRequestClient
+	requestLogOperation       *requestLogOperation // The Terraform operation that the requests of the clients are attributed to, if any
 }
 
 func (m *OracleClients) GetClient(name string) interface{} {
+	if m.requestLogOperation != nil {
+		return m.requestLogOperation.getClient(name, m.clientMap[name].sdkClient)
+	}
 	return m.clientMap[name].sdkClient
 }
 
@@ -50,6 +54,9 @@ func (m *OracleClients) FunctionsInvokeClient(endpoint string) (*oci_functions.F
 		if err = configureClient(&client.BaseClient); err != nil {
 			return nil, err
 		}
+		if m.requestLogOperation != nil {
+			m.requestLogOperation.setDispatcher(&client.BaseClient)
+		}
 		return &client, nil
 	} else {
 		return nil, err
@@ -61,6 +68,9 @@ func (m *OracleClients) KmsCryptoClient(endpoint string) (*oci_kms.KmsCryptoClie
 		if err = configureClient(&client.BaseClient); err != nil {
 			return nil, err
 		}
+		if m.requestLogOperation != nil {
+			m.requestLogOperation.setDispatcher(&client.BaseClient)
+		}
 		return &client, nil
 	} else {
 		return nil, err
@@ -72,6 +82,9 @@ func (m *OracleClients) KmsManagementClient(endpoint string) (*oci_kms.KmsManage
 		if err = configureClient(&client.BaseClient); err != nil {
 			return nil, err
 		}
+		if m.requestLogOperation != nil {
+			m.requestLogOperation.setDispatcher(&client.BaseClient)
+		}
 		return &client, nil
 	} else {
 		return nil, err
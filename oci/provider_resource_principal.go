@@ -0,0 +1,34 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	oci_common_auth "github.com/oracle/oci-go-sdk/common/auth"
+)
+
+// Signs requests with the resource principal session token (RPST) of the OCI Function, Data Science job or other
+// resource that the provider runs in. The token, private key and region are read from the OCI_RESOURCE_PRINCIPAL_*
+// environment variables, and a token read from a file is read again shortly before it expires.
+type resourcePrincipalConfigProvider struct {
+	oci_common_auth.ConfigurationProviderWithClaimAccess
+	region string // Overrides the region of the environment, if set
+}
+
+func newResourcePrincipalConfigProvider(region string) (*resourcePrincipalConfigProvider, error) {
+	cfg, err := oci_common_auth.ResourcePrincipalConfigurationProvider()
+	if err != nil {
+		return nil, err
+	}
+	return &resourcePrincipalConfigProvider{ConfigurationProviderWithClaimAccess: cfg, region: region}, nil
+}
+
+func (p *resourcePrincipalConfigProvider) String() string {
+	return "Configuration provided by resource principal"
+}
+
+func (p *resourcePrincipalConfigProvider) Region() (string, error) {
+	if p.region != "" {
+		return p.region, nil
+	}
+	return p.ConfigurationProviderWithClaimAccess.Region()
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	oci_common_auth "github.com/oracle/oci-go-sdk/common/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+// Sets the resource principal environment variables, and returns a function that restores them
+func setResourcePrincipalEnv(rpst string, privatePem string, passphrase string) func() {
+	env := map[string]string{
+		oci_common_auth.ResourcePrincipalVersionEnvVar:              oci_common_auth.ResourcePrincipalVersion2_2,
+		oci_common_auth.ResourcePrincipalRPSTEnvVar:                 rpst,
+		oci_common_auth.ResourcePrincipalPrivatePEMEnvVar:           privatePem,
+		oci_common_auth.ResourcePrincipalPrivatePEMPassphraseEnvVar: passphrase,
+		oci_common_auth.ResourcePrincipalRegionEnvVar:               "us-ashburn-1",
+	}
+
+	prevEnv := map[string]*string{}
+	for name, value := range env {
+		if prevValue, exists := os.LookupEnv(name); exists {
+			prevEnv[name] = &prevValue
+		} else {
+			prevEnv[name] = nil
+		}
+		os.Setenv(name, value)
+	}
+
+	return func() {
+		for name, prevValue := range prevEnv {
+			if prevValue == nil {
+				os.Unsetenv(name)
+			} else {
+				os.Setenv(name, *prevValue)
+			}
+		}
+	}
+}
+
+func TestUnitResourcePrincipalConfigProvider_files(t *testing.T) {
+	fixturesDir, err := ioutil.TempDir("", "resourcePrincipal")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(fixturesDir)
+
+	tokenFile := filepath.Join(fixturesDir, "rpst")
+	keyFile := filepath.Join(fixturesDir, "private.pem")
+	passphraseFile := filepath.Join(fixturesDir, "passphrase")
+	writeFixture := func(fileName string, contents string) {
+		if err := ioutil.WriteFile(fileName, []byte(contents), 0600); err != nil {
+			t.Fatalf("unable to write %s: %v", fileName, err)
+		}
+	}
+	writeFixture(keyFile, testPrivateKey)
+	writeFixture(passphraseFile, "password")
+
+	// A token that is about to expire is read again from its file
+	expiringToken := getTestSecurityToken(time.Now().Add(time.Minute))
+	writeFixture(tokenFile, expiringToken)
+	defer setResourcePrincipalEnv(tokenFile, keyFile, passphraseFile)()
+
+	provider, err := newResourcePrincipalConfigProvider("")
+	if err != nil {
+		t.Fatalf("unable to create the resource principal config provider: %v", err)
+	}
+
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+expiringToken, keyId)
+
+	refreshedToken := getTestSecurityToken(time.Now().Add(time.Hour))
+	writeFixture(tokenFile, refreshedToken)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+refreshedToken, keyId)
+
+	// A token that is still valid is not read again
+	writeFixture(tokenFile, getTestSecurityToken(time.Now().Add(2*time.Hour)))
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+refreshedToken, keyId)
+
+	privateKey, err := provider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.NotNil(t, privateKey)
+
+	tenancyId, err := provider.TenancyOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, testTenancyOCID, tenancyId)
+
+	region, err := provider.Region()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-ashburn-1", region)
+
+	userId, err := provider.UserOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, "", userId)
+}
+
+func TestUnitResourcePrincipalConfigProvider_values(t *testing.T) {
+	token := getTestSecurityToken(time.Now().Add(time.Hour))
+	defer setResourcePrincipalEnv(token, testPrivateKey, "password")()
+
+	// The configured region overrides the region of the resource principal
+	provider, err := newResourcePrincipalConfigProvider("us-phoenix-1")
+	if err != nil {
+		t.Fatalf("unable to create the resource principal config provider: %v", err)
+	}
+
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+
+	region, err := provider.Region()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-phoenix-1", region)
+
+	// The key and passphrase must both be values or both be paths
+	defer setResourcePrincipalEnv(token, testPrivateKey, "/passphrase")()
+	_, err = newResourcePrincipalConfigProvider("")
+	assert.Error(t, err)
+}
+
+func TestUnitGetConfigProviders_resourcePrincipal(t *testing.T) {
+	for _, apiKeyConfigAttribute := range apiKeyConfigAttributes {
+		if getEnvSettingWithBlankDefault(apiKeyConfigAttribute) != "" {
+			t.Skip("apiKeyConfigAttributes are set through environment variables, skip the test")
+		}
+	}
+
+	token := getTestSecurityToken(time.Now().Add(time.Hour))
+	defer setResourcePrincipalEnv(token, testPrivateKey, "password")()
+
+	r := &schema.Resource{
+		Schema: schemaMap(),
+	}
+	d := r.Data(nil)
+	d.Set("auth", authResourcePrincipalSetting)
+
+	configProviders, err := getConfigProviders(d, "resourceprincipal")
+	assert.NoError(t, err)
+	assert.Len(t, configProviders, 1)
+
+	d.Set("user_ocid", testUserOCID)
+	_, err = getConfigProviders(d, "resourceprincipal")
+	assert.EqualError(t, err, fmt.Sprintf("user credentials %v should be removed from the configuration", userOcidAttrName))
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	oci_common "github.com/oracle/oci-go-sdk/common"
+)
+
+const (
+	securityTokenFileConfigKey = "security_token_file"
+	keyFileConfigKey           = "key_file"
+	passphraseConfigKey        = "pass_phrase"
+	securityTokenKeyIdPrefix   = "ST$"
+)
+
+// Signs requests with a session token, such as the one created by 'oci session authenticate', and the ephemeral key
+// that was created along with it. The token and key files are read again whenever they change, so that a token that is
+// refreshed during a long operation is picked up. The tenancy and region are provided by the other configuration providers.
+type securityTokenConfigProvider struct {
+	profile           string
+	securityTokenFile string
+	keyFile           string
+	passphrase        string
+
+	mutex                sync.Mutex
+	securityToken        string
+	securityTokenModTime time.Time
+	privateKey           *rsa.PrivateKey
+	privateKeyModTime    time.Time
+}
+
+func newSecurityTokenConfigProvider(configFilePath string, profile string) (*securityTokenConfigProvider, error) {
+	if profile == "" {
+		profile = "DEFAULT"
+	}
+
+	values, err := getConfigFileProfileValues(configFilePath, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := &securityTokenConfigProvider{
+		profile:           profile,
+		securityTokenFile: expandConfigFilePath(values[securityTokenFileConfigKey]),
+		keyFile:           expandConfigFilePath(values[keyFileConfigKey]),
+		passphrase:        values[passphraseConfigKey],
+	}
+	if provider.securityTokenFile == "" {
+		return nil, fmt.Errorf("can not get %s from profile %s of configuration file %s", securityTokenFileConfigKey, profile, configFilePath)
+	}
+	if provider.keyFile == "" {
+		return nil, fmt.Errorf("can not get %s from profile %s of configuration file %s", keyFileConfigKey, profile, configFilePath)
+	}
+
+	return provider, nil
+}
+
+func (p *securityTokenConfigProvider) String() string {
+	return fmt.Sprintf("Configuration provided by security token file: %s", p.securityTokenFile)
+}
+
+func (p *securityTokenConfigProvider) KeyID() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	fileInfo, err := os.Stat(p.securityTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("can not read security token from: '%s', Error: %q", p.securityTokenFile, err)
+	}
+
+	if p.securityToken == "" || !fileInfo.ModTime().Equal(p.securityTokenModTime) {
+		contents, err := ioutil.ReadFile(p.securityTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("can not read security token from: '%s', Error: %q", p.securityTokenFile, err)
+		}
+		if p.securityToken != "" {
+			log.Printf("[INFO] reloading the security token from '%s'", p.securityTokenFile)
+		}
+		p.securityToken = strings.TrimSpace(string(contents))
+		p.securityTokenModTime = fileInfo.ModTime()
+	}
+
+	expiresAt, err := getSecurityTokenExpiration(p.securityToken)
+	if err != nil {
+		return "", fmt.Errorf("can not parse security token from: '%s', Error: %q", p.securityTokenFile, err)
+	}
+	if !time.Now().Before(expiresAt) {
+		return "", fmt.Errorf("the security token in '%s' expired at %s, refresh it with 'oci session refresh --profile %s' or create a new one with 'oci session authenticate'", p.securityTokenFile, expiresAt.Format(time.RFC3339), p.profile)
+	}
+
+	return securityTokenKeyIdPrefix + p.securityToken, nil
+}
+
+func (p *securityTokenConfigProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	fileInfo, err := os.Stat(p.keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can not read private key from: '%s', Error: %q", p.keyFile, err)
+	}
+
+	if p.privateKey == nil || !fileInfo.ModTime().Equal(p.privateKeyModTime) {
+		pemFileContent, err := ioutil.ReadFile(p.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can not read private key from: '%s', Error: %q", p.keyFile, err)
+		}
+		privateKey, err := oci_common.PrivateKeyFromBytes(pemFileContent, &p.passphrase)
+		if err != nil {
+			return nil, err
+		}
+		p.privateKey = privateKey
+		p.privateKeyModTime = fileInfo.ModTime()
+	}
+
+	return p.privateKey, nil
+}
+
+// The tenancy and region are left to the Terraform configuration and the configuration file
+func (p *securityTokenConfigProvider) TenancyOCID() (string, error) {
+	return "", fmt.Errorf("can not get %s from security token", tenancyOcidAttrName)
+}
+
+func (p *securityTokenConfigProvider) Region() (string, error) {
+	return "", fmt.Errorf("can not get %s from security token", regionAttrName)
+}
+
+// Requests that are signed with a security token are not made on behalf of a user or an API key
+func (p *securityTokenConfigProvider) UserOCID() (string, error) {
+	return "", nil
+}
+
+func (p *securityTokenConfigProvider) KeyFingerprint() (string, error) {
+	return "", nil
+}
+
+// Signs requests with a security token and its key, and takes only the tenancy and region from the other configuration
+// providers. The composing configuration provider falls through to the next provider when one fails, which would hide
+// an expired token behind an unrelated error, or sign requests with the key ID of an API key.
+type securityTokenComposingConfigProvider struct {
+	oci_common.ConfigurationProvider
+	securityToken *securityTokenConfigProvider
+}
+
+func (p *securityTokenComposingConfigProvider) KeyID() (string, error) {
+	return p.securityToken.KeyID()
+}
+
+func (p *securityTokenComposingConfigProvider) PrivateRSAKey() (*rsa.PrivateKey, error) {
+	return p.securityToken.PrivateRSAKey()
+}
+
+func (p *securityTokenComposingConfigProvider) UserOCID() (string, error) {
+	return p.securityToken.UserOCID()
+}
+
+func (p *securityTokenComposingConfigProvider) KeyFingerprint() (string, error) {
+	return p.securityToken.KeyFingerprint()
+}
+
+// Returns the expiration time in the payload of a security token, which is a JSON Web Token
+func getSecurityTokenExpiration(securityToken string) (time.Time, error) {
+	parts := strings.Split(securityToken, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("the security token contains an invalid number of parts")
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	payload := struct {
+		Exp *int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payloadBytes, &payload); err != nil {
+		return time.Time{}, err
+	}
+	if payload.Exp == nil {
+		return time.Time{}, fmt.Errorf("the security token has no expiration time")
+	}
+
+	return time.Unix(*payload.Exp, 0), nil
+}
+
+// Returns the values of a profile in a configuration file, with lowercase keys
+func getConfigFileProfileValues(configFilePath string, profile string) (map[string]string, error) {
+	if err := checkProfile(profile, configFilePath); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var profileRegex = regexp.MustCompile(`^\[(.*)\]`)
+	values := map[string]string{}
+	inProfile := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if match := profileRegex.FindStringSubmatch(line); match != nil {
+			inProfile = match[1] == profile
+			continue
+		}
+
+		if splits := strings.SplitN(line, "=", 2); inProfile && len(splits) == 2 {
+			key := strings.ToLower(strings.TrimSpace(splits[0]))
+			if key == "passphrase" {
+				key = passphraseConfigKey
+			}
+			values[key] = strings.TrimSpace(splits[1])
+		}
+	}
+
+	return values, nil
+}
+
+func expandConfigFilePath(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(getHomeFolder(), path[2:])
+	}
+	return path
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestSecurityToken(expiresAt time.Time) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"sub":"ocid1.user.abc","res_tenant":"%s","exp":%d}`, testTenancyOCID, expiresAt.Unix())))
+	return fmt.Sprintf("%s.%s.signature", header, payload)
+}
+
+func TestUnitSecurityTokenConfigProvider(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "securityToken")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(configDir)
+
+	configFile := filepath.Join(configDir, defaultConfigFileName)
+	tokenFile := filepath.Join(configDir, "token")
+	keyFile := filepath.Join(configDir, "oci_api_key.pem")
+	config := fmt.Sprintf("[DEFAULT]\ntenancy=%s\n[SESSION]\nfingerprint=%s\nkey_file=%s\npassphrase=password\n# security_token_file=%s.old\nsecurity_token_file=%s\n[NO_TOKEN]\nkey_file=%s\n; security_token_file=%s\n",
+		testTenancyOCID, testKeyFingerPrint, keyFile, tokenFile, tokenFile, keyFile, tokenFile)
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", configFile, err)
+	}
+	if err := ioutil.WriteFile(keyFile, []byte(testPrivateKey), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", keyFile, err)
+	}
+
+	_, err = newSecurityTokenConfigProvider(configFile, "NO_TOKEN")
+	assert.EqualError(t, err, fmt.Sprintf("can not get security_token_file from profile NO_TOKEN of configuration file %s", configFile))
+
+	provider, err := newSecurityTokenConfigProvider(configFile, "SESSION")
+	if err != nil {
+		t.Fatalf("unable to create the security token config provider: %v", err)
+	}
+
+	token := getTestSecurityToken(time.Now().Add(time.Hour))
+	if err := ioutil.WriteFile(tokenFile, []byte(token+"\n"), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", tokenFile, err)
+	}
+	keyId, err := provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+
+	privateKey, err := provider.PrivateRSAKey()
+	assert.NoError(t, err)
+	assert.NotNil(t, privateKey)
+
+	// A refreshed token is read again
+	refreshedToken := getTestSecurityToken(time.Now().Add(2 * time.Hour))
+	if err := ioutil.WriteFile(tokenFile, []byte(refreshedToken), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", tokenFile, err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	os.Chtimes(tokenFile, modTime, modTime)
+	keyId, err = provider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+refreshedToken, keyId)
+
+	// An expired token fails
+	expiresAt := time.Now().Add(-time.Hour)
+	if err := ioutil.WriteFile(tokenFile, []byte(getTestSecurityToken(expiresAt)), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", tokenFile, err)
+	}
+	modTime = modTime.Add(time.Minute)
+	os.Chtimes(tokenFile, modTime, modTime)
+	_, err = provider.KeyID()
+	assert.EqualError(t, err, fmt.Sprintf("the security token in '%s' expired at %s, refresh it with 'oci session refresh --profile SESSION' or create a new one with 'oci session authenticate'", tokenFile, time.Unix(expiresAt.Unix(), 0).Format(time.RFC3339)))
+
+	// The tenancy and region are left to the other config providers
+	_, err = provider.TenancyOCID()
+	assert.Error(t, err)
+	_, err = provider.Region()
+	assert.Error(t, err)
+}
+
+func TestUnitGetSdkConfigProvider_securityToken(t *testing.T) {
+	for _, apiKeyConfigAttribute := range apiKeyConfigAttributes {
+		if getEnvSettingWithBlankDefault(apiKeyConfigAttribute) != "" {
+			t.Skip("apiKeyConfigAttributes are set through environment variables, skip the test")
+		}
+	}
+
+	configDir := filepath.Join(getHomeFolder(), defaultConfigDirName)
+	configFile := filepath.Join(configDir, defaultConfigFileName)
+	if _, err := os.Stat(configFile); err == nil {
+		t.Skip("a configuration file exists in the home folder, skip the test")
+	}
+	if _, err := os.Stat(configDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(configDir, 0700); err != nil {
+			t.Fatalf("unable to create %s: %v", configDir, err)
+		}
+		defer os.RemoveAll(configDir)
+	}
+	defer os.Remove(configFile)
+
+	tokenDir, err := ioutil.TempDir("", "securityToken")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tokenDir)
+
+	// The profile also contains an API key, which must not be used to sign requests
+	tokenFile := filepath.Join(tokenDir, "token")
+	keyFile := filepath.Join(tokenDir, "oci_api_key.pem")
+	config := fmt.Sprintf("[SESSION]\ntenancy=%s\nuser=%s\nfingerprint=%s\nregion=us-phoenix-1\nkey_file=%s\npassphrase=password\nsecurity_token_file=%s\n",
+		testTenancyOCID, testUserOCID, testKeyFingerPrint, keyFile, tokenFile)
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", configFile, err)
+	}
+	if err := ioutil.WriteFile(keyFile, []byte(testPrivateKey), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", keyFile, err)
+	}
+	token := getTestSecurityToken(time.Now().Add(time.Hour))
+	if err := ioutil.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", tokenFile, err)
+	}
+
+	r := &schema.Resource{
+		Schema: schemaMap(),
+	}
+	d := r.Data(nil)
+	d.Set("auth", authSecurityTokenSetting)
+	d.Set("config_file_profile", "SESSION")
+
+	configProvider, err := getSdkConfigProvider(d, &OracleClients{configuration: map[string]string{}})
+	if err != nil {
+		t.Fatalf("unable to get the config provider: %v", err)
+	}
+	keyId, err := configProvider.KeyID()
+	assert.NoError(t, err)
+	assert.Equal(t, "ST$"+token, keyId)
+	tenancyOcid, err := configProvider.TenancyOCID()
+	assert.NoError(t, err)
+	assert.Equal(t, testTenancyOCID, tenancyOcid)
+	region, err := configProvider.Region()
+	assert.NoError(t, err)
+	assert.Equal(t, "us-phoenix-1", region)
+
+	// A token that expires after the provider is configured fails, rather than falling through to the API key
+	expiresAt := time.Now().Add(-time.Hour)
+	if err := ioutil.WriteFile(tokenFile, []byte(getTestSecurityToken(expiresAt)), 0600); err != nil {
+		t.Fatalf("unable to write %s: %v", tokenFile, err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	os.Chtimes(tokenFile, modTime, modTime)
+	_, err = configProvider.KeyID()
+	assert.EqualError(t, err, fmt.Sprintf("the security token in '%s' expired at %s, refresh it with 'oci session refresh --profile SESSION' or create a new one with 'oci session authenticate'", tokenFile, time.Unix(expiresAt.Unix(), 0).Format(time.RFC3339)))
+}
+
+func TestUnitGetSecurityTokenExpiration(t *testing.T) {
+	expiresAt, err := getSecurityTokenExpiration(getTestSecurityToken(time.Unix(1600000000, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1600000000), expiresAt.Unix())
+
+	_, err = getSecurityTokenExpiration("not a token")
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	oci_common "github.com/oracle/oci-go-sdk/common"
+)
+
+const (
+	loadBalancerService    = "load_balancer"
+	loadBalancerApiVersion = "20170115"
+)
+
+// The rate_limit blocks of the provider, keyed by service. The block without a service is keyed by an empty string.
+var configuredRateLimits = map[string]*rateLimitConfig{}
+
+// Maps the labels of service endpoints to the services that are used as keys of the retry and rate_limit blocks, for
+// services whose endpoint label is not their service name or is not the first label of the endpoint. Core and load
+// balancer share the 'iaas' endpoint, and are told apart by their API version.
+var rateLimitServiceEndpointLabels = map[string]string{
+	"autoscaling":          "auto_scaling",
+	"bigdataservice":       "bds",
+	"datasafe":             "data_safe",
+	"digitalassistant-api": "oda",
+	"filestorage":          "file_storage",
+	"healthchecks":         "health_checks",
+	"iaas":                 coreService,
+	"kms":                  kmsService,
+	"notification":         "ons",
+	"objectstorage":        objectstorageService,
+	"oce":                  "oce",
+	"osms":                 "osmanagement",
+	"telemetry":            "monitoring",
+	"usage":                "budget",
+	"vaults":               "vault",
+}
+
+// A rate_limit block of the provider. Values of zero are not set, and leave the limits of the service as is.
+type rateLimitConfig struct {
+	requestsPerSecond float64
+	burst             int
+	maxInFlight       int
+}
+
+// Limits the rate of requests to a service with a token bucket, and the number of requests that are in flight at once.
+// A 429 response with a Retry-After header holds back all requests to the service until that time.
+type serviceRateLimiter struct {
+	service           string
+	requestsPerSecond float64 // Zero means that the rate is not limited
+	burst             float64
+	inFlight          chan struct{} // Nil if the number of requests in flight is not limited
+
+	mutex        sync.Mutex
+	tokens       float64
+	lastRefill   time.Time
+	blockedUntil time.Time
+}
+
+func newServiceRateLimiter(service string, config *rateLimitConfig) *serviceRateLimiter {
+	limiter := &serviceRateLimiter{
+		service:           service,
+		requestsPerSecond: config.requestsPerSecond,
+		burst:             math.Max(float64(config.burst), 1),
+		lastRefill:        time.Now(),
+	}
+	limiter.tokens = limiter.burst
+	if config.maxInFlight > 0 {
+		limiter.inFlight = make(chan struct{}, config.maxInFlight)
+	}
+	return limiter
+}
+
+// Waits until a request can be sent to the service. Each call must be followed by a call to release.
+func (limiter *serviceRateLimiter) acquire() {
+	if limiter.inFlight != nil {
+		limiter.inFlight <- struct{}{}
+	}
+
+	for {
+		wait := limiter.reserve()
+		if wait <= 0 {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+func (limiter *serviceRateLimiter) release() {
+	if limiter.inFlight != nil {
+		<-limiter.inFlight
+	}
+}
+
+// Takes a token if one is available, or returns how long to wait for one
+func (limiter *serviceRateLimiter) reserve() time.Duration {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+	if now.Before(limiter.blockedUntil) {
+		return limiter.blockedUntil.Sub(now)
+	}
+	if limiter.requestsPerSecond <= 0 {
+		return 0
+	}
+
+	limiter.tokens = math.Min(limiter.burst, limiter.tokens+now.Sub(limiter.lastRefill).Seconds()*limiter.requestsPerSecond)
+	limiter.lastRefill = now
+	if limiter.tokens >= 1 {
+		limiter.tokens--
+		return 0
+	}
+	return time.Duration((1 - limiter.tokens) / limiter.requestsPerSecond * float64(time.Second))
+}
+
+// Holds back all requests to the service for the given duration, and empties the token bucket so that the requests
+// that were held back are sent at the configured rate afterwards
+func (limiter *serviceRateLimiter) block(duration time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	if blockedUntil := time.Now().Add(duration); blockedUntil.After(limiter.blockedUntil) {
+		log.Printf("[DEBUG] holding back requests to service '%s' for %v after a 429 response", limiter.service, duration)
+		limiter.blockedUntil = blockedUntil
+		limiter.tokens = 0
+		limiter.lastRefill = blockedUntil
+	}
+}
+
+// Sends the requests of a client through the rate limiter of its service
+type rateLimitedDispatcher struct {
+	dispatcher oci_common.HTTPRequestDispatcher
+	limiter    *serviceRateLimiter
+}
+
+func (d *rateLimitedDispatcher) Do(r *http.Request) (*http.Response, error) {
+	d.limiter.acquire()
+	defer d.limiter.release()
+
+	response, err := d.dispatcher.Do(r)
+	if response != nil && response.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, ok := getRetryAfterDuration(response.Header); ok {
+			d.limiter.block(retryAfter)
+		}
+	}
+	return response, err
+}
+
+// Returns the duration of a Retry-After header, which is either a number of seconds or an HTTP date
+func getRetryAfterDuration(header http.Header) (time.Duration, bool) {
+	retryAfter := strings.TrimSpace(header.Get("Retry-After"))
+	if retryAfter == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseFloat(retryAfter, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if retryAt, err := http.ParseTime(retryAfter); err == nil {
+		if duration := time.Until(retryAt); duration > 0 {
+			return duration, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Returns the service of a client from the endpoint of the client, e.g. 'identity' for
+// 'https://identity.us-phoenix-1.oraclecloud.com', or an empty string if the endpoint is not known.
+func getClientService(client *oci_common.BaseClient) string {
+	host := client.Host
+	if idx := strings.Index(host, "://"); idx >= 0 {
+		host = host[idx+3:]
+	}
+	labels := strings.Split(strings.Split(host, "/")[0], ".")
+	if len(labels) < 2 {
+		// The endpoint of the client is not known yet, e.g. for KMS clients before their vault is known
+		return ""
+	}
+
+	// Endpoints may have labels before the service label, e.g. 'cell-1.streaming.us-phoenix-1.oci.oraclecloud.com'
+	for _, label := range labels {
+		if label == "iaas" && strings.Trim(client.BasePath, "/") == loadBalancerApiVersion {
+			return loadBalancerService
+		}
+		if service, exists := rateLimitServiceEndpointLabels[label]; exists {
+			return service
+		}
+		for _, service := range configurableServices {
+			if label == service {
+				return service
+			}
+		}
+	}
+	return labels[0]
+}
+
+// Returns the limits of a service. The rate_limit block of the service overrides the rate_limit block without a
+// service. Returns nil if the service is not limited.
+func getServiceRateLimitConfig(service string) *rateLimitConfig {
+	config := &rateLimitConfig{}
+	for _, key := range []string{"", service} {
+		serviceConfig, exists := configuredRateLimits[key]
+		if !exists {
+			continue
+		}
+		if serviceConfig.requestsPerSecond > 0 {
+			config.requestsPerSecond = serviceConfig.requestsPerSecond
+		}
+		if serviceConfig.burst > 0 {
+			config.burst = serviceConfig.burst
+		}
+		if serviceConfig.maxInFlight > 0 {
+			config.maxInFlight = serviceConfig.maxInFlight
+		}
+	}
+
+	if config.requestsPerSecond <= 0 && config.maxInFlight <= 0 {
+		return nil
+	}
+	return config
+}
+
+// Returns a function that sends the requests of a client through the rate limiter of its service. The limiters are
+// shared by all the clients of a service.
+func getRateLimitClientFn() func(client *oci_common.BaseClient) {
+	limiters := map[string]*serviceRateLimiter{}
+	mutex := sync.Mutex{}
+
+	return func(client *oci_common.BaseClient) {
+		service := getClientService(client)
+		config := getServiceRateLimitConfig(service)
+		if config == nil || client.HTTPClient == nil {
+			return
+		}
+
+		mutex.Lock()
+		limiter, exists := limiters[service]
+		if !exists {
+			limiter = newServiceRateLimiter(service, config)
+			limiters[service] = limiter
+		}
+		mutex.Unlock()
+
+		client.HTTPClient = &rateLimitedDispatcher{dispatcher: client.HTTPClient, limiter: limiter}
+	}
+}
+
+// Reads the rate_limit blocks of the provider
+func getConfiguredRateLimits(d *schema.ResourceData) (map[string]*rateLimitConfig, error) {
+	rateLimits := map[string]*rateLimitConfig{}
+
+	rateLimitBlocks, _ := d.Get(rateLimitAttrName).([]interface{})
+	for _, rateLimitBlock := range rateLimitBlocks {
+		block, _ := rateLimitBlock.(map[string]interface{})
+		service, _ := block["service"].(string)
+		if _, exists := rateLimits[service]; exists {
+			if service == "" {
+				return nil, fmt.Errorf("only one %s block can be specified without a service", rateLimitAttrName)
+			}
+			return nil, fmt.Errorf("only one %s block can be specified for service '%s'", rateLimitAttrName, service)
+		}
+
+		if err := validateConfigurableService(rateLimitAttrName, service); err != nil {
+			return nil, err
+		}
+
+		config := &rateLimitConfig{}
+		config.requestsPerSecond, _ = block["requests_per_second"].(float64)
+		config.burst, _ = block["burst"].(int)
+		config.maxInFlight, _ = block["max_in_flight"].(int)
+		rateLimits[service] = config
+	}
+
+	return rateLimits, nil
+}
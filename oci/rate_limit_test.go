@@ -0,0 +1,216 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	oci_common "github.com/oracle/oci-go-sdk/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRateLimitDispatcher struct {
+	inFlight    int32
+	maxInFlight int32
+	delay       time.Duration
+	response    *http.Response
+}
+
+func (d *testRateLimitDispatcher) Do(r *http.Request) (*http.Response, error) {
+	inFlight := atomic.AddInt32(&d.inFlight, 1)
+	defer atomic.AddInt32(&d.inFlight, -1)
+	for {
+		maxInFlight := atomic.LoadInt32(&d.maxInFlight)
+		if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&d.maxInFlight, maxInFlight, inFlight) {
+			break
+		}
+	}
+
+	time.Sleep(d.delay)
+	if d.response != nil {
+		return d.response, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+}
+
+func TestUnitRateLimitedDispatcher_requestsPerSecond(t *testing.T) {
+	limiter := newServiceRateLimiter(identityService, &rateLimitConfig{requestsPerSecond: 20, burst: 5})
+	dispatcher := &rateLimitedDispatcher{dispatcher: &testRateLimitDispatcher{}, limiter: limiter}
+
+	// The burst is sent at once, and the other requests at the configured rate
+	start := time.Now()
+	for i := 0; i < 15; i++ {
+		_, err := dispatcher.Do(&http.Request{})
+		assert.NoError(t, err)
+	}
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 450*time.Millisecond, "requests were sent in %v", elapsed)
+	assert.True(t, elapsed < 2*time.Second, "requests were sent in %v", elapsed)
+}
+
+func TestUnitRateLimitedDispatcher_maxInFlight(t *testing.T) {
+	testDispatcher := &testRateLimitDispatcher{delay: 20 * time.Millisecond}
+	limiter := newServiceRateLimiter(identityService, &rateLimitConfig{maxInFlight: 3})
+	dispatcher := &rateLimitedDispatcher{dispatcher: testDispatcher, limiter: limiter}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dispatcher.Do(&http.Request{})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(3), testDispatcher.maxInFlight)
+	assert.Equal(t, int32(0), testDispatcher.inFlight)
+}
+
+func TestUnitRateLimitedDispatcher_retryAfter(t *testing.T) {
+	testDispatcher := &testRateLimitDispatcher{
+		response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"0.3"}}},
+	}
+	limiter := newServiceRateLimiter(loadBalancerService, &rateLimitConfig{requestsPerSecond: 100, burst: 10})
+	dispatcher := &rateLimitedDispatcher{dispatcher: testDispatcher, limiter: limiter}
+
+	response, err := dispatcher.Do(&http.Request{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+
+	// The next request is held back until the service accepts requests again
+	testDispatcher.response = nil
+	start := time.Now()
+	_, err = dispatcher.Do(&http.Request{})
+	assert.NoError(t, err)
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 250*time.Millisecond, "request was held back for %v", elapsed)
+}
+
+func TestUnitGetRetryAfterDuration(t *testing.T) {
+	duration, ok := getRetryAfterDuration(http.Header{"Retry-After": []string{"2"}})
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, duration)
+
+	duration, ok = getRetryAfterDuration(http.Header{"Retry-After": []string{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}})
+	assert.True(t, ok)
+	assert.True(t, duration > 50*time.Second && duration <= time.Minute, "duration is %v", duration)
+
+	duration, ok = getRetryAfterDuration(http.Header{"Retry-After": []string{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}})
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), duration)
+
+	_, ok = getRetryAfterDuration(http.Header{})
+	assert.False(t, ok)
+	_, ok = getRetryAfterDuration(http.Header{"Retry-After": []string{"soon"}})
+	assert.False(t, ok)
+}
+
+func TestUnitGetClientService(t *testing.T) {
+	testCases := []struct {
+		host     string
+		basePath string
+		service  string
+	}{
+		{host: "identity.us-phoenix-1.oraclecloud.com", service: identityService},
+		{host: "https://iaas.us-phoenix-1.oraclecloud.com", basePath: "20160918", service: coreService},
+		{host: "https://iaas.us-phoenix-1.oraclecloud.com", basePath: "20170115", service: loadBalancerService},
+		{host: "https://objectstorage.us-phoenix-1.oraclecloud.com", service: objectstorageService},
+		{host: "https://database.us-phoenix-1.oraclecloud.com", basePath: "20160918", service: "database"},
+		{host: "https://abcdef-management.kms.us-phoenix-1.oraclecloud.com", service: "kms"},
+		{host: "https://cp.oce.us-phoenix-1.oraclecloud.com", service: "oce"},
+		{host: "https://cell-1.streaming.us-phoenix-1.oci.oraclecloud.com", service: "streaming"},
+		{host: "https://unknown.us-phoenix-1.oraclecloud.com", service: "unknown"},
+		{host: "DUMMY_ENDPOINT", service: ""},
+	}
+
+	for _, testCase := range testCases {
+		client := &oci_common.BaseClient{Host: testCase.host, BasePath: testCase.basePath}
+		assert.Equal(t, testCase.service, getClientService(client), "host %s", testCase.host)
+	}
+}
+
+func TestUnitGetRateLimitClientFn(t *testing.T) {
+	prevRateLimits := configuredRateLimits
+	defer func() { configuredRateLimits = prevRateLimits }()
+
+	configuredRateLimits = map[string]*rateLimitConfig{
+		"":              {requestsPerSecond: 10, burst: 5},
+		identityService: {maxInFlight: 2},
+	}
+
+	// The limits of a service override the limits of all services
+	assert.Equal(t, &rateLimitConfig{requestsPerSecond: 10, burst: 5, maxInFlight: 2}, getServiceRateLimitConfig(identityService))
+	assert.Equal(t, &rateLimitConfig{requestsPerSecond: 10, burst: 5}, getServiceRateLimitConfig(coreService))
+
+	// The clients of a service share a limiter
+	rateLimitClient := getRateLimitClientFn()
+	identityClient := &oci_common.BaseClient{Host: "identity.us-phoenix-1.oraclecloud.com", HTTPClient: &http.Client{}}
+	otherIdentityClient := &oci_common.BaseClient{Host: "identity.us-ashburn-1.oraclecloud.com", HTTPClient: &http.Client{}}
+	coreClient := &oci_common.BaseClient{Host: "https://iaas.us-phoenix-1.oraclecloud.com", BasePath: "20160918", HTTPClient: &http.Client{}}
+	rateLimitClient(identityClient)
+	rateLimitClient(otherIdentityClient)
+	rateLimitClient(coreClient)
+
+	identityDispatcher, ok := identityClient.HTTPClient.(*rateLimitedDispatcher)
+	assert.True(t, ok)
+	otherIdentityDispatcher, ok := otherIdentityClient.HTTPClient.(*rateLimitedDispatcher)
+	assert.True(t, ok)
+	coreDispatcher, ok := coreClient.HTTPClient.(*rateLimitedDispatcher)
+	assert.True(t, ok)
+	assert.True(t, identityDispatcher.limiter == otherIdentityDispatcher.limiter)
+	assert.False(t, identityDispatcher.limiter == coreDispatcher.limiter)
+
+	// Clients are not limited if no limits are configured
+	configuredRateLimits = map[string]*rateLimitConfig{}
+	client := &oci_common.BaseClient{Host: "identity.us-phoenix-1.oraclecloud.com", HTTPClient: &http.Client{}}
+	getRateLimitClientFn()(client)
+	_, ok = client.HTTPClient.(*http.Client)
+	assert.True(t, ok)
+}
+
+func TestUnitGetConfiguredRateLimits(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		rateLimitAttrName: []interface{}{
+			map[string]interface{}{
+				"requests_per_second": 2.5,
+				"burst":               5,
+			},
+			map[string]interface{}{
+				"service":       loadBalancerService,
+				"max_in_flight": 4,
+			},
+		},
+	})
+
+	rateLimits, err := getConfiguredRateLimits(d)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*rateLimitConfig{
+		"":                  {requestsPerSecond: 2.5, burst: 5},
+		loadBalancerService: {maxInFlight: 4},
+	}, rateLimits)
+
+	d = schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		rateLimitAttrName: []interface{}{
+			map[string]interface{}{"service": identityService, "max_in_flight": 4},
+			map[string]interface{}{"service": identityService, "burst": 5},
+		},
+	})
+	_, err = getConfiguredRateLimits(d)
+	assert.EqualError(t, err, "only one rate_limit block can be specified for service 'identity'")
+
+	d = schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		rateLimitAttrName: []interface{}{
+			map[string]interface{}{"service": "identiy", "max_in_flight": 4},
+		},
+	})
+	_, err = getConfiguredRateLimits(d)
+	assert.EqualError(t, err, fmt.Sprintf("the service 'identiy' of a rate_limit block must be one of: %s", strings.Join(configurableServices, ", ")))
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2017, 2019, Oracle and/or its affiliates. All rights reserved.
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	oci_apigateway "github.com/oracle/oci-go-sdk/apigateway"
+	oci_common "github.com/oracle/oci-go-sdk/common"
+	oci_work_requests "github.com/oracle/oci-go-sdk/workrequests"
+)
+
+const (
+	requestLogRedactedValue = "REDACTED"
+	requestLogMaxBodyLength = 64 * 1024 // Larger bodies are not logged
+)
+
+// The request_log_file of the provider. Requests are not logged if it is empty.
+var configuredRequestLogFile string
+
+var (
+	requestLogFiles      = map[string]*requestLogFile{} // Keyed by path, so that all the provider configurations that log to a file share it
+	requestLogFilesMutex sync.Mutex
+)
+
+// Headers and fields whose names contain any of these, ignoring case, dashes and underscores, are redacted
+var requestLogSecretNames = []string{"authorization", "obotoken", "cookie", "password", "passphrase", "privatekey", "secret", "wallet", "plaintext", "credential", "token"}
+
+var sdkOperationRegex = regexp.MustCompile(`\ngithub\.com/oracle/oci-go-sdk/([\w/]+)\.\(?\*?\w+\)?\.([a-z]\w*)\(`)
+
+// The key of the Terraform operation in the context of a request
+type requestLogOperationKey struct{}
+
+// A line of the request log
+type requestLogEntry struct {
+	Time              string            `json:"time"`
+	Service           string            `json:"service,omitempty"`
+	Operation         string            `json:"operation,omitempty"`
+	Method            string            `json:"method"`
+	URL               string            `json:"url"`
+	Status            int               `json:"status,omitempty"`
+	OpcRequestId      string            `json:"opc_request_id,omitempty"`
+	LatencyMs         int64             `json:"latency_ms"`
+	Attempt           int               `json:"attempt,omitempty"`
+	Resource          string            `json:"resource,omitempty"`
+	ResourceId        string            `json:"resource_id,omitempty"`
+	ResourceOperation string            `json:"resource_operation,omitempty"`
+	Error             string            `json:"error,omitempty"`
+	RequestHeaders    map[string]string `json:"request_headers,omitempty"`
+	RequestBody       json.RawMessage   `json:"request_body,omitempty"`
+	ResponseBody      json.RawMessage   `json:"response_body,omitempty"`
+}
+
+type requestLogFile struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+func getRequestLogFile(path string) (*requestLogFile, error) {
+	requestLogFilesMutex.Lock()
+	defer requestLogFilesMutex.Unlock()
+
+	if logFile, exists := requestLogFiles[path]; exists {
+		return logFile, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("can not open %s '%s': %v", requestLogFileAttrName, path, err)
+	}
+	logFile := &requestLogFile{file: file}
+	requestLogFiles[path] = logFile
+	return logFile, nil
+}
+
+func (logFile *requestLogFile) write(entry *requestLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	logFile.mutex.Lock()
+	defer logFile.mutex.Unlock()
+	logFile.file.Write(append(line, '\n'))
+}
+
+// A Terraform operation on a resource or data source, to which the requests that it sends are attributed
+type requestLogOperation struct {
+	terraformType string // e.g. 'oci_core_vcn'
+	operation     string // e.g. 'Create'
+	d             *schema.ResourceData
+
+	mutex   sync.Mutex
+	clients map[string]interface{} // Copies of the clients that the operation sends requests with, keyed by name
+
+	// The last request of the operation, to tell the retries of a request from new requests
+	lastRequest string
+	lastAttempt int
+	lastFailed  bool
+}
+
+// Returns copies of resources or data sources whose functions attribute the requests that they send to their
+// Terraform type, ID and operation in the request log
+func withRequestLogOperations(resources map[string]*schema.Resource) map[string]*schema.Resource {
+	result := make(map[string]*schema.Resource, len(resources))
+	for terraformType, resource := range resources {
+		resourceCopy := *resource
+		resourceCopy.Create = getRequestLogOperationFn(terraformType, "Create", resource.Create)
+		resourceCopy.Read = getRequestLogOperationFn(terraformType, "Read", resource.Read)
+		resourceCopy.Update = getRequestLogOperationFn(terraformType, "Update", resource.Update)
+		resourceCopy.Delete = getRequestLogOperationFn(terraformType, "Delete", resource.Delete)
+		result[terraformType] = &resourceCopy
+	}
+	return result
+}
+
+// Returns a function that calls fn with clients that attribute their requests to the operation, if requests are logged
+func getRequestLogOperationFn(terraformType string, operation string, fn func(*schema.ResourceData, interface{}) error) func(*schema.ResourceData, interface{}) error {
+	if fn == nil {
+		return nil
+	}
+
+	return func(d *schema.ResourceData, m interface{}) error {
+		clients, ok := m.(*OracleClients)
+		if configuredRequestLogFile == "" || !ok || clients.requestLogOperation != nil {
+			return fn(d, m)
+		}
+
+		return fn(d, clients.withRequestLogOperation(&requestLogOperation{
+			terraformType: terraformType,
+			operation:     operation,
+			d:             d,
+			clients:       map[string]interface{}{},
+		}))
+	}
+}
+
+// Returns a copy of the clients whose requests are attributed to a Terraform operation
+func (m *OracleClients) withRequestLogOperation(operation *requestLogOperation) *OracleClients {
+	clients := *m
+	clients.requestLogOperation = operation
+	if m.gatewayWorkRequestsClient != nil {
+		clients.gatewayWorkRequestsClient = operation.getClient("oci_apigateway.WorkRequestsClient", m.gatewayWorkRequestsClient).(*oci_apigateway.WorkRequestsClient)
+	}
+	if m.workRequestClient != nil {
+		clients.workRequestClient = operation.getClient("oci_work_requests.WorkRequestClient", m.workRequestClient).(*oci_work_requests.WorkRequestClient)
+	}
+	return &clients
+}
+
+// Returns a copy of an SDK client that attributes its requests to the operation. The clients are shared by all
+// operations, so they are copied rather than changed.
+func (operation *requestLogOperation) getClient(name string, client interface{}) interface{} {
+	operation.mutex.Lock()
+	defer operation.mutex.Unlock()
+
+	if clientCopy, exists := operation.clients[name]; exists {
+		return clientCopy
+	}
+
+	value := reflect.ValueOf(client)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return client
+	}
+	if baseClient := value.Elem().FieldByName("BaseClient"); !baseClient.IsValid() || baseClient.Type() != reflect.TypeOf(oci_common.BaseClient{}) {
+		return client
+	}
+
+	clientCopy := reflect.New(value.Elem().Type())
+	clientCopy.Elem().Set(value.Elem())
+	operation.setDispatcher(clientCopy.Elem().FieldByName("BaseClient").Addr().Interface().(*oci_common.BaseClient))
+	operation.clients[name] = clientCopy.Interface()
+	return operation.clients[name]
+}
+
+func (operation *requestLogOperation) setDispatcher(client *oci_common.BaseClient) {
+	if client.HTTPClient != nil {
+		client.HTTPClient = &requestLogOperationDispatcher{dispatcher: client.HTTPClient, operation: operation}
+	}
+}
+
+// Returns the attempt of a request. A request is a retry if it repeats the last request of the operation after that
+// request failed. Requests that create resources are told apart by their retry token, which is the same for every attempt.
+func (operation *requestLogOperation) getAttempt(r *http.Request) int {
+	request := strings.Join([]string{r.Method, r.URL.String(), r.Header.Get("opc-retry-token")}, " ")
+
+	operation.mutex.Lock()
+	defer operation.mutex.Unlock()
+	if request == operation.lastRequest && operation.lastFailed {
+		operation.lastAttempt++
+	} else {
+		operation.lastRequest = request
+		operation.lastAttempt = 1
+	}
+	return operation.lastAttempt
+}
+
+func (operation *requestLogOperation) setFailed(failed bool) {
+	operation.mutex.Lock()
+	defer operation.mutex.Unlock()
+	operation.lastFailed = failed
+}
+
+// Adds the Terraform operation of a client to the context of its requests
+type requestLogOperationDispatcher struct {
+	dispatcher oci_common.HTTPRequestDispatcher
+	operation  *requestLogOperation
+}
+
+func (d *requestLogOperationDispatcher) Do(r *http.Request) (*http.Response, error) {
+	return d.dispatcher.Do(r.WithContext(context.WithValue(r.Context(), requestLogOperationKey{}, d.operation)))
+}
+
+// Returns the SDK operation that sends a request, e.g. 'CreateLoadBalancer' for the createLoadBalancer function of
+// the LoadBalancerClient, which implements the CreateLoadBalancer operation
+func getRequestLogSdkOperation(stack []byte) string {
+	for _, match := range sdkOperationRegex.FindAllSubmatch(stack, -1) {
+		if string(match[1]) == "common" || strings.HasPrefix(string(match[1]), "common/") {
+			continue
+		}
+		operation := string(match[2])
+		return strings.ToUpper(operation[:1]) + operation[1:]
+	}
+	return ""
+}
+
+// Writes every request of a client to the request log
+type requestLogDispatcher struct {
+	dispatcher oci_common.HTTPRequestDispatcher
+	logFile    *requestLogFile
+	service    string
+}
+
+func (d *requestLogDispatcher) Do(r *http.Request) (*http.Response, error) {
+	stack := make([]byte, 16*1024)
+	stack = stack[:runtime.Stack(stack, false)]
+
+	entry := &requestLogEntry{
+		Service:        d.service,
+		Operation:      getRequestLogSdkOperation(stack),
+		Method:         r.Method,
+		URL:            getRedactedURL(r),
+		RequestHeaders: getRedactedHeaders(r.Header),
+		RequestBody:    getRequestLogRequestBody(r),
+	}
+	operation, _ := r.Context().Value(requestLogOperationKey{}).(*requestLogOperation)
+	if operation != nil {
+		entry.Attempt = operation.getAttempt(r)
+		entry.Resource = operation.terraformType
+		entry.ResourceId = operation.d.Id()
+		entry.ResourceOperation = operation.operation
+	}
+
+	start := time.Now()
+	response, err := d.dispatcher.Do(r)
+	entry.Time = start.UTC().Format(time.RFC3339Nano)
+	entry.LatencyMs = time.Since(start).Nanoseconds() / int64(time.Millisecond)
+
+	entry.OpcRequestId = r.Header.Get("opc-request-id")
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if response != nil {
+		entry.Status = response.StatusCode
+		if opcRequestId := response.Header.Get("opc-request-id"); opcRequestId != "" {
+			entry.OpcRequestId = opcRequestId
+		}
+		entry.ResponseBody = getRequestLogResponseBody(response)
+	}
+	if operation != nil {
+		operation.setFailed(err != nil || response == nil || response.StatusCode >= http.StatusBadRequest)
+	}
+
+	d.logFile.write(entry)
+	return response, err
+}
+
+// Returns a function that writes the requests of a client to the request_log_file, if it is configured
+func getRequestLogClientFn() (func(client *oci_common.BaseClient), error) {
+	if configuredRequestLogFile == "" {
+		return func(client *oci_common.BaseClient) {}, nil
+	}
+
+	logFile, err := getRequestLogFile(expandConfigFilePath(configuredRequestLogFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(client *oci_common.BaseClient) {
+		if client.HTTPClient == nil {
+			return
+		}
+		client.HTTPClient = &requestLogDispatcher{dispatcher: client.HTTPClient, logFile: logFile, service: getClientService(client)}
+	}, nil
+}
+
+func isRequestLogSecretName(name string) bool {
+	name = strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+	for _, secretName := range requestLogSecretNames {
+		if strings.Contains(name, secretName) {
+			return true
+		}
+	}
+	return false
+}
+
+func getRedactedHeaders(header http.Header) map[string]string {
+	redacted := map[string]string{}
+	for name, values := range header {
+		if isRequestLogSecretName(name) {
+			redacted[name] = requestLogRedactedValue
+		} else {
+			redacted[name] = strings.Join(values, ", ")
+		}
+	}
+	return redacted
+}
+
+func getRedactedURL(r *http.Request) string {
+	if r.URL == nil {
+		return ""
+	}
+
+	url := *r.URL
+	url.User = nil
+	query := url.Query()
+	for name := range query {
+		if isRequestLogSecretName(name) {
+			query.Set(name, requestLogRedactedValue)
+		}
+	}
+	url.RawQuery = query.Encode()
+	return url.String()
+}
+
+// Returns a JSON body with its secret fields redacted, or nil if the body is not JSON
+func getRedactedBody(body []byte) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+
+	redacted, err := json.Marshal(redactRequestLogValue(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redactRequestLogValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range v {
+			if isRequestLogSecretName(key) {
+				v[key] = requestLogRedactedValue
+			} else {
+				v[key] = redactRequestLogValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactRequestLogValue(item)
+		}
+	}
+	return value
+}
+
+func isRequestLogJSON(header http.Header, contentLength int64) bool {
+	return strings.Contains(header.Get("Content-Type"), "json") && contentLength <= requestLogMaxBodyLength
+}
+
+func getRequestLogRequestBody(r *http.Request) json.RawMessage {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength <= 0 || !isRequestLogJSON(r.Header, r.ContentLength) {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return getRedactedBody(body)
+}
+
+func getRequestLogResponseBody(response *http.Response) json.RawMessage {
+	if response.Body == nil || !isRequestLogJSON(response.Header, response.ContentLength) {
+		return nil
+	}
+
+	// The response body is read up to the maximum length, and put back in front of the rest of it
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, requestLogMaxBodyLength+1))
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), response.Body), response.Body}
+	if err != nil || len(body) > requestLogMaxBodyLength {
+		return nil
+	}
+	return getRedactedBody(body)
+}
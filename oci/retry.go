@@ -3,18 +3,22 @@
 package oci
 
 import (
+	"fmt"
 	"math/rand"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform/helper/schema"
 	oci_common "github.com/oracle/oci-go-sdk/common"
 
 	"github.com/terraform-providers/terraform-provider-oci/httpreplay"
 )
 
 const (
-	quadraticBackoffCap  = 12              // This corresponds to a 2*12*12=288 second cap on retry wait times (~5 minutes)
-	minRetryBackoff      = 1 * time.Second // Must wait for at least 1 second before retrying
+	quadraticBackoffCap  = 12              // By default, this corresponds to a 2*12*12=288 second cap on retry wait times (~5 minutes)
+	minRetryBackoff      = 1 * time.Second // By default, must wait for at least 1 second before retrying
 	databaseService      = "database"
 	identityService      = "identity"
 	coreService          = "core"
@@ -31,6 +35,7 @@ type expectedRetryDurationFn func(response oci_common.OCIOperationResponse, disa
 type serviceExpectedRetryDurationFunc func(response oci_common.OCIOperationResponse, disableNotFoundRetries bool, optionals ...interface{}) time.Duration
 type getRetryPolicyFunc func(disableNotFoundRetries bool, service string, optionals ...interface{}) *oci_common.RetryPolicy
 
+// The built-in retry behaviour of services that don't use the default
 var serviceExpectedRetryDurationMap = map[string]serviceExpectedRetryDurationFunc{
 	coreService:          getCoreExpectedRetryDuration,
 	databaseService:      getDatabaseExpectedRetryDuration,
@@ -46,6 +51,46 @@ var shortRetryTime = 2 * time.Minute
 var longRetryTime = 10 * time.Minute
 var configuredRetryDuration *time.Duration
 
+// The retry blocks of the provider, keyed by service. The block without a service is keyed by an empty string.
+var configuredRetryPolicies = map[string]*retryPolicyConfig{}
+
+// Matches the HTTP statuses of the retryable_status_codes of a retry block, e.g. '429' or '5xx'. 404 errors are only
+// retried by retry_not_found_after_create.
+var retryableStatusCodeRegex = regexp.MustCompile(`^(4(0[0-35-9]|[1-9][0-9]|xx)|5([0-9]{2}|xx))$`)
+
+// The services that retry and rate_limit blocks can be specified for, which are the services that resources get their
+// retry policies for
+var configurableServices = []string{
+	"analytics", "apigateway", "audit", "auto_scaling", "bds", "budget", "containerengine", coreService, "data_safe",
+	databaseService, "datacatalog", "dataflow", "datascience", "dns", "email", "events", "file_storage", "functions",
+	"health_checks", identityService, "integration", kmsService, "limits", loadBalancerService, "marketplace",
+	"monitoring", "nosql", objectstorageService, "oce", "oda", "ons", "osmanagement", "resourcemanager", "streaming",
+	"vault", waasService,
+}
+
+// A retry block of the provider. Fields that are not set are nil, and leave the retry behaviour as is.
+type retryPolicyConfig struct {
+	maxAttempts              *uint
+	maxDuration              *time.Duration
+	baseBackoff              *time.Duration
+	maxBackoff               *time.Duration
+	retryableStatusCodes     []string
+	retryableConflictCodes   []string
+	retryNotFoundAfterCreate *bool
+}
+
+// The retry behaviour of a service, resolved from its built-in retry behaviour and the retry blocks of the provider
+type serviceRetryPolicy struct {
+	maxAttempts              uint           // Zero means that the number of attempts is only limited by the retry duration
+	maxDuration              *time.Duration // Replaces the retry duration of all retryable errors, if set
+	baseBackoff              time.Duration
+	maxBackoff               time.Duration
+	retryableStatusCodes     []string // Replaces the built-in retryable HTTP statuses other than 404 and 409, if set
+	retryableConflictCodes   []string // Replaces the built-in retryable error codes of 409 responses, if set
+	retryNotFoundAfterCreate bool
+	expectedRetryDurationFn  serviceExpectedRetryDurationFunc
+}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -59,15 +104,22 @@ func getRetryBackoffDurationWithExpectedRetryDurationFn(response oci_common.OCIO
 		return 0
 	}
 
+	policy := getServiceRetryPolicy(service)
+
 	// Avoid having a very large retry backoff
-	attempt := response.AttemptNumber
-	if attempt > quadraticBackoffCap {
-		attempt = quadraticBackoffCap
+	retryBackoff := policy.maxBackoff
+	if attempt := time.Duration(response.AttemptNumber); attempt < 1<<15 {
+		if quadraticBackoff := 2 * attempt * attempt * time.Second; quadraticBackoff < retryBackoff {
+			retryBackoff = quadraticBackoff
+		}
+	}
+	retryBackoffRange := retryBackoff - policy.baseBackoff
+	if retryBackoffRange < 0 {
+		retryBackoffRange = 0
 	}
-	retryBackoffRange := time.Duration(2*attempt*attempt)*time.Second - minRetryBackoff
 
 	// Jitter the backoff time. The actual backoff time might be anywhere within the minimum and quadratic backoff time to avoid clustering.
-	backoffDuration := time.Duration(rand.Int63n(int64(retryBackoffRange+1))) + minRetryBackoff
+	backoffDuration := time.Duration(rand.Int63n(int64(retryBackoffRange+1))) + policy.baseBackoff
 
 	// If we are about to exceed the retry duration; then reduce the backoff so that next attempt happens roughly when
 	// the entire retry duration is supposed to expire. Jitter is necessary again to avoid clustering.
@@ -75,7 +127,7 @@ func getRetryBackoffDurationWithExpectedRetryDurationFn(response oci_common.OCIO
 	timeWaited := getElapsedRetryDuration(startTime)
 	if timeWaited < expectedRetryDuration && timeWaited+backoffDuration > expectedRetryDuration {
 		extraJitterRange := int64(float64(expectedRetryDuration) * 0.05)
-		finalBackoffDuration := expectedRetryDuration - timeWaited + time.Duration(rand.Int63n(extraJitterRange+1)) + minRetryBackoff
+		finalBackoffDuration := expectedRetryDuration - timeWaited + time.Duration(rand.Int63n(extraJitterRange+1)) + policy.baseBackoff
 		if finalBackoffDuration < backoffDuration {
 			backoffDuration = finalBackoffDuration
 		}
@@ -88,12 +140,176 @@ func getElapsedRetryDuration(firstAttemptTime time.Time) time.Duration {
 }
 
 func getExpectedRetryDuration(response oci_common.OCIOperationResponse, disableNotFoundRetries bool, service string, optionals ...interface{}) time.Duration {
+	return getServiceRetryPolicy(service).getExpectedRetryDuration(response, disableNotFoundRetries, optionals...)
+}
 
+// Returns the retry policy of a service. The retry block of the service overrides the retry block without a service,
+// which overrides the built-in retry behaviour of the service.
+func getServiceRetryPolicy(service string) *serviceRetryPolicy {
+	policy := &serviceRetryPolicy{
+		baseBackoff:              minRetryBackoff,
+		maxBackoff:               time.Duration(2*quadraticBackoffCap*quadraticBackoffCap) * time.Second,
+		retryNotFoundAfterCreate: true,
+		expectedRetryDurationFn: func(response oci_common.OCIOperationResponse, disableNotFoundRetries bool, optionals ...interface{}) time.Duration {
+			return getDefaultExpectedRetryDuration(response, disableNotFoundRetries)
+		},
+	}
 	if retryDurationFn, ok := serviceExpectedRetryDurationMap[service]; ok {
-		return retryDurationFn(response, disableNotFoundRetries, optionals...)
+		policy.expectedRetryDurationFn = retryDurationFn
+	}
+
+	policy.apply(configuredRetryPolicies[""])
+	if service != "" {
+		policy.apply(configuredRetryPolicies[service])
+	}
+	return policy
+}
+
+func (policy *serviceRetryPolicy) apply(config *retryPolicyConfig) {
+	if config == nil {
+		return
+	}
+	if config.maxAttempts != nil {
+		policy.maxAttempts = *config.maxAttempts
+	}
+	if config.maxDuration != nil {
+		policy.maxDuration = config.maxDuration
+	}
+	if config.baseBackoff != nil {
+		policy.baseBackoff = *config.baseBackoff
+	}
+	if config.maxBackoff != nil {
+		policy.maxBackoff = *config.maxBackoff
+	}
+	if config.retryableStatusCodes != nil {
+		policy.retryableStatusCodes = config.retryableStatusCodes
+	}
+	if config.retryableConflictCodes != nil {
+		policy.retryableConflictCodes = config.retryableConflictCodes
+	}
+	if config.retryNotFoundAfterCreate != nil {
+		policy.retryNotFoundAfterCreate = *config.retryNotFoundAfterCreate
+	}
+}
+
+func (policy *serviceRetryPolicy) getExpectedRetryDuration(response oci_common.OCIOperationResponse, disableNotFoundRetries bool, optionals ...interface{}) time.Duration {
+	expectedRetryDuration := policy.expectedRetryDurationFn(response, disableNotFoundRetries, optionals...)
+	if response.Response == nil || response.Response.HTTPResponse() == nil {
+		return expectedRetryDuration
+	}
+
+	switch statusCode := response.Response.HTTPResponse().StatusCode; {
+	case statusCode >= 200 && statusCode < 300:
+		return 0
+	case statusCode == 404:
+		// Resources that were just created may not be found yet, which is only retried if not-found retries aren't disabled
+		if !policy.retryNotFoundAfterCreate {
+			return 0
+		}
+	case statusCode == 409 && policy.retryableConflictCodes != nil:
+		expectedRetryDuration = 0
+		if e := response.Error; e != nil {
+			for _, conflictCode := range policy.retryableConflictCodes {
+				if strings.Contains(e.Error(), conflictCode) {
+					expectedRetryDuration = longRetryTime
+					break
+				}
+			}
+		}
+	case statusCode == 409:
+		if policy.retryableStatusCodes != nil && !policy.isRetryableStatusCode(statusCode) {
+			return 0
+		}
+	case policy.retryableStatusCodes != nil:
+		if !policy.isRetryableStatusCode(statusCode) {
+			return 0
+		}
+		if expectedRetryDuration == 0 {
+			expectedRetryDuration = shortRetryTime
+		}
+	}
+
+	if expectedRetryDuration > 0 && policy.maxDuration != nil {
+		return *policy.maxDuration
 	}
+	return expectedRetryDuration
+}
 
-	return getDefaultExpectedRetryDuration(response, disableNotFoundRetries)
+func (policy *serviceRetryPolicy) isRetryableStatusCode(statusCode int) bool {
+	for _, retryableStatusCode := range policy.retryableStatusCodes {
+		if retryableStatusCode == strconv.Itoa(statusCode) || retryableStatusCode == fmt.Sprintf("%dxx", statusCode/100) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reads the retry blocks of the provider. Values of zero are treated as not set.
+func getConfiguredRetryPolicies(d *schema.ResourceData) (map[string]*retryPolicyConfig, error) {
+	retryPolicies := map[string]*retryPolicyConfig{}
+
+	retryBlocks, _ := d.Get(retryAttrName).([]interface{})
+	for idx, retryBlock := range retryBlocks {
+		block, _ := retryBlock.(map[string]interface{})
+		service, _ := block["service"].(string)
+		if _, exists := retryPolicies[service]; exists {
+			if service == "" {
+				return nil, fmt.Errorf("only one %s block can be specified without a service", retryAttrName)
+			}
+			return nil, fmt.Errorf("only one %s block can be specified for service '%s'", retryAttrName, service)
+		}
+
+		if err := validateConfigurableService(retryAttrName, service); err != nil {
+			return nil, err
+		}
+
+		config := &retryPolicyConfig{}
+		if maxAttempts, _ := block["max_attempts"].(int); maxAttempts > 0 {
+			tmp := uint(maxAttempts)
+			config.maxAttempts = &tmp
+		}
+		for attrName, duration := range map[string]**time.Duration{
+			"max_duration_seconds": &config.maxDuration,
+			"base_backoff_seconds": &config.baseBackoff,
+			"max_backoff_seconds":  &config.maxBackoff,
+		} {
+			if seconds, _ := block[attrName].(int); seconds > 0 {
+				tmp := time.Duration(seconds) * time.Second
+				*duration = &tmp
+			}
+		}
+		for attrName, values := range map[string]*[]string{
+			"retryable_status_codes":   &config.retryableStatusCodes,
+			"retryable_conflict_codes": &config.retryableConflictCodes,
+		} {
+			items, _ := block[attrName].([]interface{})
+			for _, item := range items {
+				*values = append(*values, item.(string))
+			}
+		}
+		if retryNotFoundAfterCreate, exists := d.GetOkExists(fmt.Sprintf("%s.%d.retry_not_found_after_create", retryAttrName, idx)); exists {
+			tmp := retryNotFoundAfterCreate.(bool)
+			config.retryNotFoundAfterCreate = &tmp
+		}
+
+		retryPolicies[service] = config
+	}
+
+	return retryPolicies, nil
+}
+
+// Returns an error if a service that a retry or rate_limit block is specified for is not known, since the block would
+// have no effect
+func validateConfigurableService(attrName string, service string) error {
+	if service == "" {
+		return nil
+	}
+	for _, configurableService := range configurableServices {
+		if service == configurableService {
+			return nil
+		}
+	}
+	return fmt.Errorf("the service '%s' of a %s block must be one of: %s", service, attrName, strings.Join(configurableServices, ", "))
 }
 
 func getDefaultExpectedRetryDuration(response oci_common.OCIOperationResponse, disableNotFoundRetries bool) time.Duration {
@@ -226,7 +442,7 @@ func getRetryPolicy(disableNotFoundRetries bool, service string, optionals ...in
 func getDefaultRetryPolicy(disableNotFoundRetries bool, service string, optionals ...interface{}) *oci_common.RetryPolicy {
 	startTime := time.Now()
 	retryPolicy := &oci_common.RetryPolicy{
-		MaximumNumberAttempts: 0,
+		MaximumNumberAttempts: getServiceRetryPolicy(service).maxAttempts,
 		ShouldRetryOperation: func(response oci_common.OCIOperationResponse) bool {
 			return shouldRetry(response, disableNotFoundRetries, service, startTime, optionals...)
 		},
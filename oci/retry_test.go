@@ -5,10 +5,14 @@ package oci
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/terraform-providers/terraform-provider-oci/httpreplay"
 
 	"github.com/oracle/oci-go-sdk/common"
@@ -312,3 +316,123 @@ func TestUnitRetryObjectStorage(t *testing.T) {
 	}
 	retryLoop(t, &r)
 }
+
+func TestUnitGetServiceRetryPolicy(t *testing.T) {
+	shortRetryTime = 15 * time.Second
+	longRetryTime = 30 * time.Second
+	configuredRetryDuration = nil
+	maxAttempts := uint(3)
+	maxDuration := 45 * time.Second
+	retryNotFoundAfterCreate := false
+	configuredRetryPolicies = map[string]*retryPolicyConfig{
+		"":              {maxAttempts: &maxAttempts, retryableStatusCodes: []string{"409", "429", "502", "503"}},
+		databaseService: {maxDuration: &maxDuration, retryableConflictCodes: []string{"IncorrectState"}, retryNotFoundAfterCreate: &retryNotFoundAfterCreate},
+	}
+	defer func() { configuredRetryPolicies = map[string]*retryPolicyConfig{} }()
+
+	getExpectedRetryDurationFor := func(service string, statusCode int, err error) time.Duration {
+		response := common.NewOCIOperationResponse(TestOCIResponse{statusCode: statusCode}, err, 1)
+		return getExpectedRetryDuration(response, false, service)
+	}
+
+	// The retry block without a service applies to all services
+	assert.Equal(t, uint(3), getRetryPolicy(false, coreService).MaximumNumberAttempts)
+	assert.Equal(t, shortRetryTime, getExpectedRetryDurationFor(coreService, 502, fmt.Errorf("BadGateway")))
+	assert.Equal(t, time.Duration(0), getExpectedRetryDurationFor(coreService, 500, fmt.Errorf("InternalServerError")), "a 500 is retried by default, but not by the retry block")
+	assert.Equal(t, time.Duration(0), getExpectedRetryDurationFor(coreService, 412, nil))
+	assert.Equal(t, shortRetryTime, getExpectedRetryDurationFor(coreService, 404, nil))
+
+	// The retry block of a service overrides the retry block without a service
+	assert.Equal(t, uint(3), getRetryPolicy(false, databaseService).MaximumNumberAttempts)
+	assert.Equal(t, maxDuration, getExpectedRetryDurationFor(databaseService, 409, fmt.Errorf("IncorrectState")))
+	assert.Equal(t, time.Duration(0), getExpectedRetryDurationFor(databaseService, 409, fmt.Errorf("Conflict")), "a conflict is retried by default, but not by the retry block")
+	assert.Equal(t, time.Duration(0), getExpectedRetryDurationFor(databaseService, 404, nil))
+	assert.Equal(t, maxDuration, getExpectedRetryDurationFor(databaseService, 429, nil))
+
+	// Services keep their built-in conflict handling, unless the retry block has conflict codes
+	assert.Equal(t, longRetryTime, getExpectedRetryDurationFor(identityService, 409, fmt.Errorf("NotAuthorizedOrResourceAlreadyExists")))
+	assert.Equal(t, time.Duration(0), getExpectedRetryDurationFor(identityService, 409, fmt.Errorf("InvalidatedRetryToken")))
+}
+
+func TestUnitGetRetryBackoffDuration_configuredBackoff(t *testing.T) {
+	if httpreplay.ModeRecordReplay() {
+		t.Skip("Skip Retry Tests in HttpReplay mode.")
+	}
+	shortRetryTime = 15 * time.Second
+	longRetryTime = 30 * time.Second
+	configuredRetryDuration = nil
+	baseBackoff := 2 * time.Second
+	maxBackoff := 5 * time.Second
+	configuredRetryPolicies = map[string]*retryPolicyConfig{coreService: {baseBackoff: &baseBackoff, maxBackoff: &maxBackoff}}
+	defer func() { configuredRetryPolicies = map[string]*retryPolicyConfig{} }()
+
+	for attempt := uint(1); attempt <= 20; attempt++ {
+		response := common.NewOCIOperationResponse(TestOCIResponse{statusCode: 429}, fmt.Errorf("TooManyRequests"), attempt)
+		backoff := getRetryBackoffDuration(response, false, coreService, time.Now())
+		if backoff < baseBackoff || backoff > maxBackoff {
+			t.Errorf("Expected wait time to be between %v and %v for attempt %v, but got %v", baseBackoff, maxBackoff, attempt, backoff)
+		}
+	}
+}
+
+func TestUnitGetConfiguredRetryPolicies(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		"retry": []interface{}{
+			map[string]interface{}{
+				"max_attempts":           5,
+				"retryable_status_codes": []interface{}{"429", "5xx"},
+			},
+			map[string]interface{}{
+				"service":                      "database",
+				"max_duration_seconds":         600,
+				"base_backoff_seconds":         2,
+				"max_backoff_seconds":          60,
+				"retryable_conflict_codes":     []interface{}{"IncorrectState"},
+				"retry_not_found_after_create": false,
+			},
+		},
+	})
+
+	retryPolicies, err := getConfiguredRetryPolicies(d)
+	assert.NoError(t, err)
+	assert.Len(t, retryPolicies, 2)
+
+	defaultPolicy := retryPolicies[""]
+	assert.Equal(t, uint(5), *defaultPolicy.maxAttempts)
+	assert.Equal(t, []string{"429", "5xx"}, defaultPolicy.retryableStatusCodes)
+	assert.Nil(t, defaultPolicy.maxDuration)
+	assert.Nil(t, defaultPolicy.retryNotFoundAfterCreate)
+
+	databasePolicy := retryPolicies["database"]
+	assert.Nil(t, databasePolicy.maxAttempts)
+	assert.Equal(t, 10*time.Minute, *databasePolicy.maxDuration)
+	assert.Equal(t, 2*time.Second, *databasePolicy.baseBackoff)
+	assert.Equal(t, time.Minute, *databasePolicy.maxBackoff)
+	assert.Equal(t, []string{"IncorrectState"}, databasePolicy.retryableConflictCodes)
+	assert.False(t, *databasePolicy.retryNotFoundAfterCreate)
+
+	d = schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		"retry": []interface{}{
+			map[string]interface{}{"service": "core", "max_attempts": 5},
+			map[string]interface{}{"service": "core", "max_attempts": 3},
+		},
+	})
+	_, err = getConfiguredRetryPolicies(d)
+	assert.EqualError(t, err, "only one retry block can be specified for service 'core'")
+
+	d = schema.TestResourceDataRaw(t, schemaMap(), map[string]interface{}{
+		"retry": []interface{}{
+			map[string]interface{}{"service": "databse", "max_attempts": 5},
+		},
+	})
+	_, err = getConfiguredRetryPolicies(d)
+	assert.EqualError(t, err, fmt.Sprintf("the service 'databse' of a retry block must be one of: %s", strings.Join(configurableServices, ", ")))
+
+	// 404 errors are only retried by retry_not_found_after_create
+	for _, statusCode := range []string{"400", "409", "429", "4xx", "500", "5xx"} {
+		assert.True(t, retryableStatusCodeRegex.MatchString(statusCode), statusCode)
+	}
+	for _, statusCode := range []string{"404", "200", "40", "4xxx", "6xx"} {
+		assert.False(t, retryableStatusCodeRegex.MatchString(statusCode), statusCode)
+	}
+}
@@ -16,6 +16,9 @@ type TfHclVersion interface {
 	getDataSourceHclString(string, string) string
 	getSingleExpHclString(string) string
 	getDoubleExpHclString(string, string) string
+	getRemoteStateHclString(string, string) string
+	getRemoteStateOutputHclString(string, string) string
+	getProviderHclString(string, string) string
 }
 
 type TfHclVersion11 struct {
@@ -42,6 +45,18 @@ func (tfversion *TfHclVersion11) getDoubleExpHclString(expString1 string, expStr
 	return fmt.Sprintf("\"${%s.%s}\"", expString1, expString2)
 }
 
+func (tfversion *TfHclVersion11) getRemoteStateHclString(remoteStateName string, statePath string) string {
+	return fmt.Sprintf("data terraform_remote_state %s {\nbackend = \"local\"\nconfig {\npath = %q\n}\n}\n", remoteStateName, statePath)
+}
+
+func (tfversion *TfHclVersion11) getRemoteStateOutputHclString(remoteStateName string, outputName string) string {
+	return fmt.Sprintf("\"${data.terraform_remote_state.%s.%s}\"", remoteStateName, outputName)
+}
+
+func (tfversion *TfHclVersion11) getProviderHclString(providerType string, alias string) string {
+	return fmt.Sprintf("\"%s.%s\"", providerType, alias)
+}
+
 type TfHclVersion12 struct {
 	Value TfVersionEnum
 }
@@ -65,3 +80,15 @@ func (tfversion *TfHclVersion12) getSingleExpHclString(expString string) string
 func (tfversion *TfHclVersion12) getDoubleExpHclString(expString1 string, expString2 string) string {
 	return fmt.Sprintf("%s.%s", expString1, expString2)
 }
+
+func (tfversion *TfHclVersion12) getRemoteStateHclString(remoteStateName string, statePath string) string {
+	return fmt.Sprintf("data terraform_remote_state %s {\nbackend = \"local\"\nconfig = {\npath = %q\n}\n}\n", remoteStateName, statePath)
+}
+
+func (tfversion *TfHclVersion12) getRemoteStateOutputHclString(remoteStateName string, outputName string) string {
+	return fmt.Sprintf("data.terraform_remote_state.%s.outputs.%s", remoteStateName, outputName)
+}
+
+func (tfversion *TfHclVersion12) getProviderHclString(providerType string, alias string) string {
+	return fmt.Sprintf("%s.%s", providerType, alias)
+}
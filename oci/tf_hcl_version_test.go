@@ -316,3 +316,201 @@ func TestUnitTfHclVersion12_getDoubleExpHclString(t *testing.T) {
 		})
 	}
 }
+
+func TestUnitTfHclVersion11_getRemoteStateHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		remoteStateName string
+		statePath       string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"RemoteStateHclString",
+			fields{TfVersion11},
+			args{"export", "../terraform.tfstate"},
+			"data terraform_remote_state export {\nbackend = \"local\"\nconfig {\npath = \"../terraform.tfstate\"\n}\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion11{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getRemoteStateHclString(tt.args.remoteStateName, tt.args.statePath); got != tt.want {
+				t.Errorf("TfHclVersion11.getRemoteStateHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitTfHclVersion11_getRemoteStateOutputHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		remoteStateName string
+		outputName      string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"RemoteStateOutputHclString",
+			fields{TfVersion11},
+			args{"export", "oci_core_vcn_vcn1_id"},
+			"\"${data.terraform_remote_state.export.oci_core_vcn_vcn1_id}\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion11{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getRemoteStateOutputHclString(tt.args.remoteStateName, tt.args.outputName); got != tt.want {
+				t.Errorf("TfHclVersion11.getRemoteStateOutputHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitTfHclVersion12_getRemoteStateHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		remoteStateName string
+		statePath       string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"RemoteStateHclString",
+			fields{TfVersion12},
+			args{"export", "../terraform.tfstate"},
+			"data terraform_remote_state export {\nbackend = \"local\"\nconfig = {\npath = \"../terraform.tfstate\"\n}\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion12{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getRemoteStateHclString(tt.args.remoteStateName, tt.args.statePath); got != tt.want {
+				t.Errorf("TfHclVersion12.getRemoteStateHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitTfHclVersion12_getRemoteStateOutputHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		remoteStateName string
+		outputName      string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"RemoteStateOutputHclString",
+			fields{TfVersion12},
+			args{"export", "oci_core_vcn_vcn1_id"},
+			"data.terraform_remote_state.export.outputs.oci_core_vcn_vcn1_id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion12{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getRemoteStateOutputHclString(tt.args.remoteStateName, tt.args.outputName); got != tt.want {
+				t.Errorf("TfHclVersion12.getRemoteStateOutputHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitTfHclVersion11_getProviderHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		providerType string
+		alias        string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"ProviderHclString",
+			fields{TfVersion11},
+			args{"oci", "phx"},
+			"\"oci.phx\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion11{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getProviderHclString(tt.args.providerType, tt.args.alias); got != tt.want {
+				t.Errorf("TfHclVersion11.getProviderHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitTfHclVersion12_getProviderHclString(t *testing.T) {
+	type fields struct {
+		Value TfVersionEnum
+	}
+	type args struct {
+		providerType string
+		alias        string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		want   string
+	}{
+		{
+			"ProviderHclString",
+			fields{TfVersion12},
+			args{"oci", "phx"},
+			"oci.phx",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfversion := &TfHclVersion12{
+				Value: tt.fields.Value,
+			}
+			if got := tfversion.getProviderHclString(tt.args.providerType, tt.args.alias); got != tt.want {
+				t.Errorf("TfHclVersion12.getProviderHclString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}